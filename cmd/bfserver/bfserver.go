@@ -1,8 +1,17 @@
 package main
 
-import "github.com/viamrobotics/bfserver/service"
+import (
+	"context"
+	"os"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/viamrobotics/bfserver/service"
+)
 
 func main() {
-	server := service.NewBFServer()
-	server.Start()
+	client := github.NewTokenClient(context.Background(), os.Getenv("github_token"))
+	server := service.NewBFServer(client)
+	if err := server.Start(":8080"); err != nil {
+		panic(err)
+	}
 }
@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,7 +17,8 @@ import (
 )
 
 func main() {
-	fmt.Println("Run started:", time.Now())
+	// Stdout is kept for reports. E.g: `analyze --format json`.
+	fmt.Fprintln(os.Stderr, "Run started:", time.Now())
 	if len(os.Args) == 1 {
 		fmt.Println("Usage:\n\tbfserver discover\n\tbfserver analyze")
 		return
@@ -28,17 +31,22 @@ func main() {
 		discover()
 	case "list":
 		list()
+	case "skips":
+		skips()
+	case "timeline":
+		timeline()
 	default:
 		fmt.Printf("Unknown command: `%v`\n", os.Args[1])
-		fmt.Println("Usage:\n\tbfserver discover\n\tbfserver analyze\n\tbfserver list")
+		fmt.Println("Usage:\n\tbfserver discover\n\tbfserver analyze\n\tbfserver list\n\tbfserver skips\n\tbfserver timeline")
 		return
 	}
 
-	fmt.Println("Github Rate:", service.GithubRate())
+	fmt.Fprintln(os.Stderr, "Github Rate:", service.GithubRate())
 }
 
 func discover() {
 	arg := util.ParseProgramArgs()
+	loadNameRules()
 
 	var startDate, endDate string
 
@@ -51,7 +59,7 @@ func discover() {
 
 	switch len(emptyArgs) {
 	case 1:
-		fmt.Println("Usage: bfserver discover <start-date> <end-date?>")
+		fmt.Println("Usage: bfserver discover [--flakes] <start-date> <end-date?>")
 		return
 	case 2:
 		startDate = emptyArgs[1]
@@ -59,13 +67,13 @@ func discover() {
 		startDate = emptyArgs[1]
 		endDate = emptyArgs[2]
 	default:
-		fmt.Println("Usage: bfserver discover <start-date> <end-date?>")
+		fmt.Println("Usage: bfserver discover [--flakes] <start-date> <end-date?>")
 		return
 	}
 
 	ctx := context.Background()
 	client := arg.GetGithubClient()
-	runs, err := service.FindFailingRuns(ctx, client, startDate, endDate)
+	runs, err := service.FindFailingRuns(ctx, client, startDate, endDate, arg.Flakes)
 	if err != nil {
 		panic(err)
 	}
@@ -85,7 +93,7 @@ func discover() {
 		fmt.Println("New run:", *run.ID, "Date:", *run.RunStartedAt, "Link:", *run.HTMLURL)
 		i1 := service.NewIndenter()
 
-		failures, err := service.GithubRunToFailedTests(ctx, client, *run.Repository.Name, *run.ID, int64(0))
+		failures, err := service.GithubRunToFailedTests(ctx, client, *run.Repository.Name, *run.ID, int64(0), arg.Flakes)
 		if err != nil {
 			fmt.Println("Error finding failures:", err)
 			if arg.FileTickets {
@@ -179,6 +187,29 @@ func writeSeenCache(runId int64) {
 	cacheFile.WriteString(fmt.Sprintf("%v\n", runId))
 }
 
+// Prepends the optional custom test name rules in `<config>/bfserver/name_rules` to the built-in
+// rules. See `service.ParseNameRules` for the format.
+func loadNameRules() {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		panic(err)
+	}
+
+	rulesFile, err := os.Open(fmt.Sprintf("%v/bfserver/name_rules", configDir))
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		panic(err)
+	}
+	defer rulesFile.Close()
+
+	rules, err := service.ParseNameRules(rulesFile)
+	if err != nil {
+		panic(err)
+	}
+	service.TestNameNormalizer = service.NewNameNormalizer(append(rules, service.DefaultNameRules...)...)
+}
+
 func list() {
 	var jiraUsername, jiraToken string
 	configDir, err := os.UserConfigDir()
@@ -226,6 +257,11 @@ func list() {
 
 func analyze() {
 	args := util.ParseProgramArgs()
+	loadNameRules()
+	if args.Format != "" && !slices.Contains(service.ReportFormats, args.Format) {
+		fmt.Printf("Unknown format: `%v`. Expected one of: %v\n", args.Format, strings.Join(service.ReportFormats, ", "))
+		return
+	}
 
 	ctx := context.Background()
 	client := args.GetGithubClient()
@@ -253,11 +289,11 @@ func analyze() {
 
 	var failures []service.Failure
 	if args.IsJob {
-		failures, err = service.GithubRunToFailedTests(ctx, client, repo, runId, jobId)
+		failures, err = service.GithubRunToFailedTests(ctx, client, repo, runId, jobId, args.Flakes)
 	} else if args.IsRun {
 		// Passing zero gets failures for all jobs in the run
 		allJobs := int64(0)
-		failures, err = service.GithubRunToFailedTests(ctx, client, repo, runId, allJobs)
+		failures, err = service.GithubRunToFailedTests(ctx, client, repo, runId, allJobs, args.Flakes)
 	} else {
 		fmt.Println("Must pass --job or --run")
 	}
@@ -265,18 +301,22 @@ func analyze() {
 		panic(err)
 	}
 
-	fmt.Println("Num failures:", len(failures))
-	for _, failure := range failures {
-		fmt.Printf("%v\n", failure.Variant)
-		fmt.Println("---------------------------")
-		failure.Output.PrettyPrint("\t")
-	}
+	if args.Format != "" {
+		writeReport(args, repo, runId, failures)
+	} else {
+		fmt.Println("Num failures:", len(failures))
+		for _, failure := range failures {
+			fmt.Printf("%v\n", failure.Variant)
+			fmt.Println("---------------------------")
+			failure.Output.PrettyPrint("\t")
+		}
 
-	fmt.Println("Summary:")
-	for _, failure := range failures {
-		fmt.Printf("  %v\n", failure.Variant)
-		fmt.Println("  ---------------------------")
-		failure.Output.ThingsThatFailed("  ", failure)
+		fmt.Println("Summary:")
+		for _, failure := range failures {
+			fmt.Printf("  %v\n", failure.Variant)
+			fmt.Println("  ---------------------------")
+			failure.Output.ThingsThatFailed("  ", failure)
+		}
 	}
 
 	if args.Dedup || args.FileTickets {
@@ -287,8 +327,8 @@ func analyze() {
 			fmt.Println("Deduping\n---------------------------")
 			fmt.Println("All failures:", failures)
 			for _, failure := range failures {
-				fmt.Println("Test failures:", failure.Output.TestFailures)
-				for _, fqTest := range failure.Output.TestFailures {
+				fmt.Println("Test failures:", failure.Output.TestFailures, "Flakes:", len(failure.Output.Flakes))
+				for _, fqTest := range failure.Output.FailuresToTicket() {
 					err := service.RunDedup(failure, fqTest, openTickets)
 					if err != nil {
 						fmt.Println("Failed to run dedup/find test failure details. Test:", fqTest, " Err:", err)
@@ -318,3 +358,166 @@ func analyze() {
 		}
 	}
 }
+
+// Writes the failures in the `--format` to the `--output` file, or stdout.
+func writeReport(args *util.Arg, repo string, runId int64, failures []service.Failure) {
+	writer := os.Stdout
+	if args.OutputPath != "" {
+		reportFile, err := os.Create(args.OutputPath)
+		if err != nil {
+			panic(err)
+		}
+		defer reportFile.Close()
+		writer = reportFile
+	}
+
+	if err := service.WriteReport(writer, args.Format, repo, runId, failures); err != nil {
+		panic(err)
+	}
+}
+
+// Reports the skipped tests of each variant of a run and how they changed since the last time the
+// command was run for that repo and variant. The run's inventory then becomes the one to compare
+// against.
+func skips() {
+	args := util.ParseProgramArgs()
+
+	ctx := context.Background()
+	client := args.GetGithubClient()
+
+	// Example url: https://github.com/viamrobotics/rdk/actions/runs/5859328480
+	runRe := regexp.MustCompile(`/([^/]*?)/actions/runs/(\d+)`)
+	matches := runRe.FindStringSubmatch(args.Url)
+	if len(matches) == 0 {
+		fmt.Println("Usage: bfserver skips <github run url>")
+		return
+	}
+
+	repo := matches[1]
+	runId, err := strconv.ParseInt(matches[2], 10, 64)
+	if err != nil {
+		fmt.Println("Error parsing the run id from the link:", args.Url)
+		panic(err)
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		panic(err)
+	}
+
+	// Passing zero gets the output for all jobs in the run.
+	outputs, err := service.GithubRunToTestOutputs(ctx, client, repo, runId, int64(0))
+	if err != nil {
+		panic(err)
+	}
+
+	for _, output := range outputs {
+		fmt.Printf("%v\n", output.Variant)
+		fmt.Println("---------------------------")
+
+		inventoryPath := service.SkipInventoryPath(configDir, repo, output.Variant)
+		previous, err := service.LoadSkipInventory(inventoryPath)
+		if err != nil {
+			panic(err)
+		}
+		current := service.NewSkipInventory(repo, runId, output)
+		drift := service.DiffSkipInventories(previous, current)
+
+		closedTickets, err := service.GetClosedTickets(args.JiraUsername, args.JiraToken, current.TicketRefs())
+		if err != nil {
+			fmt.Println("Error finding closed tickets:", err)
+		} else {
+			drift.AddClosedTicketRefs(current, closedTickets)
+		}
+		drift.PrettyPrint("\t", current)
+
+		if err := current.Save(inventoryPath); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Writes a trace of when each test ran, for a local test log artifact or for each variant of a
+// github run or job. E.g: `timeline-amd64.json`. Traces open in https://ui.perfetto.dev or
+// chrome://tracing. With `--html`, a standalone HTML page is written next to each trace.
+func timeline() {
+	args := util.ParseProgramArgs()
+	ctx := context.Background()
+
+	var target string
+	for _, arg := range os.Args[2:] {
+		if !strings.HasPrefix(arg, "-") {
+			target = arg
+		}
+	}
+	if target == "" {
+		fmt.Println("Usage: bfserver timeline <artifact|github run or job url> [--html]")
+		return
+	}
+
+	type namedOutput struct {
+		name   string
+		output *service.Output
+	}
+	outputs := make([]namedOutput, 0)
+	if strings.HasPrefix(target, "http") {
+		// Example url: https://github.com/viamrobotics/rdk/actions/runs/5859328480/job/15885094207
+		runJobRe := regexp.MustCompile(`/([^/]*?)/actions/runs/(\d+)(?:/job/(\d+))?`)
+		matches := runJobRe.FindStringSubmatch(target)
+		if len(matches) == 0 {
+			fmt.Println("Usage: bfserver timeline <artifact|github run or job url> [--html]")
+			return
+		}
+
+		repo := matches[1]
+		runId, err := strconv.ParseInt(matches[2], 10, 64)
+		if err != nil {
+			fmt.Println("Error parsing the run id from the link:", target)
+			panic(err)
+		}
+		// Zero gets the output for all jobs in the run.
+		jobId := int64(0)
+		if matches[3] != "" {
+			jobId, err = strconv.ParseInt(matches[3], 10, 64)
+			if err != nil {
+				fmt.Println("Error parsing the job id from the link:", target)
+				panic(err)
+			}
+		}
+
+		failures, err := service.GithubRunToTestOutputs(ctx, args.GetGithubClient(), repo, runId, jobId)
+		if err != nil {
+			panic(err)
+		}
+		for _, failure := range failures {
+			outputs = append(outputs, namedOutput{"timeline-" + failure.Variant, failure.Output})
+		}
+	} else {
+		output, err := service.ParseTestLogFile(ctx, target)
+		if err != nil {
+			panic(err)
+		}
+		outputs = append(outputs, namedOutput{"timeline", output})
+	}
+
+	for _, output := range outputs {
+		trace := output.output.Timeline()
+		writeTimelineFile(output.name+".json", trace.WriteJSON)
+		if args.Html {
+			writeTimelineFile(output.name+".html", trace.WriteHTML)
+		}
+	}
+}
+
+func writeTimelineFile(path string, write func(io.Writer) error) {
+	timelineFile, err := os.Create(path)
+	if err != nil {
+		panic(err)
+	}
+	defer timelineFile.Close()
+
+	if err := write(timelineFile); err != nil {
+		panic(err)
+	}
+	fmt.Println("Wrote:", path)
+}
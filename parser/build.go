@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A package that failed to compile or failed `go vet`. Build failures indicate a broken main
+// rather than a flaky test.
+type BuildFailure struct {
+	// The package that failed to build. Test packages that depend on it also fail to build.
+	Package string
+	// The test packages that could not be run due to the build failure.
+	AffectedPackages []string
+	Errors           []CompileError
+	LogLines         []string
+}
+
+type CompileError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	// True for `go vet` failures. False for compiler errors.
+	Vet bool
+}
+
+// E.g: "FAIL\tgo.viam.com/rdk/robot/impl [build failed]"
+var buildFailedRe *regexp.Regexp = regexp.MustCompile(
+	`^FAIL\t(\S+) \[(?:build|setup) failed\]$`)
+
+// E.g: "robot/impl/local_robot.go:123:2: undefined: foo"
+// E.g: "vet: robot/impl/local_robot_test.go:42:3: fmt.Sprintf format %d has arg x of wrong type string"
+var compileErrorRe *regexp.Regexp = regexp.MustCompile(
+	`^(vet: )?(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// Strips the build variant off of an import path. E.g: `go.viam.com/rdk/robot/impl
+// [go.viam.com/rdk/robot/impl.test]` returns `go.viam.com/rdk/robot/impl`.
+func importPathToPackage(importPath string) string {
+	pkg, _, _ := strings.Cut(importPath, " ")
+	return pkg
+}
+
+// Parses compiler and vet errors out of `go build` result. E.g:
+//
+//	# go.viam.com/rdk/robot/impl [go.viam.com/rdk/robot/impl.test]
+//	robot/impl/local_robot.go:123:2: undefined: foo
+//	# [go.viam.com/rdk/robot/impl]
+//	robot/impl/local_robot_test.go:42:3: fmt.Sprintf format %d has arg x of wrong type string
+//
+// A `# [<pkg>]` header starts `go vet` result.
+func parseBuildOutput(lines []string) []CompileError {
+	ret := make([]CompileError, 0)
+	inVet := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "# ") {
+			inVet = strings.HasPrefix(line, "# [")
+			continue
+		}
+
+		matches := compileErrorRe.FindStringSubmatch(line)
+		if len(matches) == 0 {
+			continue
+		}
+
+		compileError := CompileError{
+			File:    matches[2],
+			Line:    atoi(matches[3]),
+			Message: matches[5],
+			Vet:     inVet || matches[1] != "",
+		}
+		if matches[4] != "" {
+			compileError.Column = atoi(matches[4])
+		}
+		ret = append(ret, compileError)
+	}
+
+	return ret
+}
+
+// E.g: "robot/impl/local_robot.go:123:2: undefined: foo"
+func (compileError CompileError) ToPrettyString() string {
+	prefix := ""
+	if compileError.Vet {
+		prefix = "vet: "
+	}
+
+	return fmt.Sprintf("%s%v:%d:%d: %v",
+		prefix, compileError.File, compileError.Line, compileError.Column, compileError.Message)
+}
+
+func (failure *BuildFailure) ToPrettyString(indent string) string {
+	lines := make([]string, 0, len(failure.Errors)+1)
+	for _, compileError := range failure.Errors {
+		lines = append(lines, indent+compileError.ToPrettyString())
+	}
+	if len(failure.Errors) == 0 {
+		lines = append(lines, fmt.Sprintf("%s%v [build failed]", indent, failure.Package))
+	}
+	if len(failure.AffectedPackages) > 1 ||
+		(len(failure.AffectedPackages) == 1 && failure.AffectedPackages[0] != failure.Package) {
+		lines = append(lines, fmt.Sprintf("%sAffected packages: %v",
+			indent, strings.Join(failure.AffectedPackages, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestBuildFailure(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	const dependent = "go.viam.com/rdk/robot/web"
+
+	// Go 1.24+ reports the compiler output with `build-output` actions.
+	output, err := Parse(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "build-output", ImportPath: pkg + " [" + pkg + ".test]", Output: "# " + pkg + " [" + pkg + ".test]\n"},
+		TestLogLine{Action: "build-output", ImportPath: pkg + " [" + pkg + ".test]", Output: "robot/impl/local_robot.go:123:2: undefined: foo\n"},
+		TestLogLine{Action: "build-output", ImportPath: pkg + " [" + pkg + ".test]", Output: "# [" + pkg + "]\n"},
+		TestLogLine{Action: "build-output", ImportPath: pkg + " [" + pkg + ".test]", Output: "vet: robot/impl/local_robot_test.go:42:3: unreachable code\n"},
+		TestLogLine{Action: "build-fail", ImportPath: pkg + " [" + pkg + ".test]"},
+		TestLogLine{Action: "output", Package: pkg, Output: "FAIL\t" + pkg + " [build failed]\n"},
+		TestLogLine{Action: "fail", Package: pkg, FailedBuild: pkg + " [" + pkg + ".test]"},
+		TestLogLine{Action: "output", Package: dependent, Output: "FAIL\t" + dependent + " [build failed]\n"},
+		TestLogLine{Action: "fail", Package: dependent, FailedBuild: pkg + " [" + pkg + ".test]"},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(output.PackageFailures) != 0 || len(output.BuildFailures) != 1 {
+		t.Fatalf("Wrong failures. PackageFailures: %v BuildFailures: %+v",
+			output.PackageFailures, output.BuildFailures)
+	}
+
+	buildFailure := output.BuildFailures[FQTest(pkg)]
+	if len(buildFailure.AffectedPackages) != 2 || len(buildFailure.Errors) != 2 {
+		t.Fatalf("Wrong build failure: %+v", buildFailure)
+	}
+	compileError, vetError := buildFailure.Errors[0], buildFailure.Errors[1]
+	if compileError.File != "robot/impl/local_robot.go" || compileError.Line != 123 ||
+		compileError.Column != 2 || compileError.Vet {
+		t.Fatalf("Wrong compile error: %+v", compileError)
+	}
+	if vetError.Message != "unreachable code" || !vetError.Vet {
+		t.Fatalf("Wrong vet error: %+v", vetError)
+	}
+
+	// Older go versions only report the package as failing to build.
+	output, err = Parse(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "output", Package: pkg, Output: "FAIL\t" + pkg + " [build failed]\n"},
+		TestLogLine{Action: "fail", Package: pkg},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := output.BuildFailures[FQTest(pkg)]; !exists || len(output.PackageFailures) != 0 {
+		t.Fatalf("Expected a build failure. Output: %+v", output)
+	}
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A test binary crash. Either a panic or a go fatal error. E.g:
+//
+//	panic: close of closed channel
+//	fatal error: concurrent map writes
+//	fatal error: all goroutines are asleep - deadlock!
+type CrashFailure struct {
+	Package string
+	// The test that was running when the binary crashed. Empty if it could not be determined.
+	Test string
+	// `panic` or `fatal error`.
+	Kind string
+	// E.g: `runtime error: invalid memory address or nil pointer dereference`.
+	Value string
+	// The goroutine that crashed. Nil if the stack could not be parsed.
+	Goroutine *Goroutine
+	// The tests that were running when the binary crashed, if go printed them.
+	RunningTests []RunningTest
+	LogLines     []string
+}
+
+// E.g: "panic: runtime error: index out of range [1] with length 1"
+// E.g: "panic: boom [recovered]"
+// E.g: "fatal error: concurrent map writes"
+var startCrashRe *regexp.Regexp = regexp.MustCompile(
+	`^(panic|fatal error): (.*?)(?: \[recovered(?:, repanicked)?\])?$`)
+
+func newCrashFailure(doc TestLogLine) *CrashFailure {
+	matches := startCrashRe.FindStringSubmatch(doc.Output)
+	return &CrashFailure{
+		Package:  doc.Package,
+		Test:     doc.Test,
+		Kind:     matches[1],
+		Value:    matches[2],
+		LogLines: []string{doc.Output},
+	}
+}
+
+// Returns true for a `panic: runtime error: ...` crash.
+func (crash *CrashFailure) IsRuntimeError() bool {
+	return crash.Kind == "panic" && strings.HasPrefix(crash.Value, "runtime error:")
+}
+
+// Fills in the `Goroutine` and `RunningTests` from the crash's log lines. If the test to blame is
+// not yet known, it is taken from go's running tests, else from the test function on the crashed
+// goroutine's stack.
+func (crash *CrashFailure) parseLogLines(ownModulePrefixes []string) {
+	// The crashed goroutine is the first one printed.
+	if goroutines := parseGoroutineDump(crash.LogLines, ownModulePrefixes); len(goroutines) > 0 {
+		crash.Goroutine = goroutines[0]
+	}
+	crash.RunningTests = parseRunningTests(crash.LogLines)
+
+	if crash.Test != "" {
+		return
+	}
+
+	runningTests := crash.RunningTests
+	if crash.Goroutine != nil && len(runningTests) == 0 {
+		runningTests = runningTestsFromGoroutines(crash.Package, []*Goroutine{crash.Goroutine})
+	}
+	crash.Test = longestRunningLeafTest(runningTests)
+}
+
+func (crash *CrashFailure) ToFQTest() FQTest {
+	return TestLogLine{Package: crash.Package, Test: crash.Test}.ToFQTest()
+}
+
+// Returns the ticket summary prefix for the kind of crash. Runtime errors keep their historical
+// `RuntimeError` prefix.
+func (crash *CrashFailure) SummaryPrefix() string {
+	switch {
+	case crash.IsRuntimeError():
+		return "Test RuntimeError"
+	case crash.Kind == "fatal error":
+		return "Test Fatal Error"
+	default:
+		return "Test Panic"
+	}
+}
+
+// E.g:
+//
+//	panic: close of closed channel
+//	1 goroutine [running]:
+//	  => go.viam.com/rdk/robot/impl.(*localRobot).Close robot.go:123
+//	     testing.tRunner testing.go:1446
+func (crash *CrashFailure) ToPrettyString(indent string) string {
+	ret := fmt.Sprintf("%s%v: %v", indent, crash.Kind, crash.Value)
+	if crash.Goroutine != nil {
+		ret = fmt.Sprintf("%v\n%v", ret, summarizeGoroutines([]*Goroutine{crash.Goroutine}, indent))
+	}
+
+	return ret
+}
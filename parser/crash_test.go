@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestCrashAttribution(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	packageOutput := func(output string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Output: output + "\n"}
+	}
+
+	output, err := Parse(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "output", Package: pkg, Test: "TestClose", Output: "=== RUN   TestClose\n"},
+		packageOutput("panic: close of closed channel [recovered]"),
+		packageOutput("\tpanic: close of closed channel"),
+		packageOutput(""),
+		packageOutput("goroutine 7 [running]:"),
+		packageOutput("testing.tRunner.func1.2({0x1013c20, 0x10a1f30})"),
+		packageOutput("\t/usr/local/go/src/testing/testing.go:1631 +0x1c4"),
+		packageOutput(pkg+".(*localRobot).Close(...)"),
+		packageOutput("\t/__w/rdk/rdk/robot/impl/local_robot.go:123"),
+		packageOutput(pkg+".TestClose(0xc000007860)"),
+		packageOutput("\t/__w/rdk/rdk/robot/impl/local_robot_test.go:42 +0x2c"),
+		packageOutput("testing.tRunner(0xc000007860, 0x10a1e18)"),
+		packageOutput("\t/usr/local/go/src/testing/testing.go:1689 +0xfb"),
+		packageOutput("FAIL\t"+pkg+"\t0.012s"),
+		TestLogLine{Action: "fail", Package: pkg, Elapsed: 0.012},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const fqTest = FQTest(pkg + ".TestClose")
+	crash, exists := output.Crashes[fqTest]
+	if !exists {
+		t.Fatalf("Expected crash for %v. Crashes: %+v", fqTest, output.Crashes)
+	}
+	if crash.Kind != "panic" || crash.Value != "close of closed channel" {
+		t.Fatalf("Wrong crash: %+v", crash)
+	}
+	if crash.Goroutine == nil || crash.Goroutine.ID != 7 || len(crash.Goroutine.Frames) != 4 {
+		t.Fatalf("Wrong crashed goroutine: %+v", crash.Goroutine)
+	}
+
+	// Fatal errors with go's running tests hint.
+	output, err = Parse(context.Background(), parsertest.LogReader(
+		packageOutput("fatal error: concurrent map writes"),
+		packageOutput("running tests:"),
+		packageOutput("\tTestConcurrentWrites (3s)"),
+		packageOutput(""),
+		packageOutput("goroutine 12 [running]:"),
+		packageOutput(pkg+".TestConcurrentWrites.func1()"),
+		packageOutput("\t/__w/rdk/rdk/robot/impl/local_robot_test.go:80 +0x2c"),
+		TestLogLine{Action: "fail", Package: pkg, Elapsed: 3},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crash, exists = output.Crashes[FQTest(pkg+".TestConcurrentWrites")]
+	if !exists {
+		t.Fatalf("Expected crash for TestConcurrentWrites. Crashes: %+v", output.Crashes)
+	}
+	if crash.Kind != "fatal error" || crash.Value != "concurrent map writes" || crash.SummaryPrefix() != "Test Fatal Error" {
+		t.Fatalf("Wrong crash: %+v", crash)
+	}
+
+	// Runtime errors keep their own category.
+	output, err = Parse(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "output", Package: pkg, Test: "TestNil", Output: "panic: runtime error: invalid memory address or nil pointer dereference\n"},
+		TestLogLine{Action: "fail", Package: pkg, Elapsed: 3},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := output.RuntimeErrors[FQTest(pkg+".TestNil")]; !exists || len(output.Crashes) != 0 {
+		t.Fatalf("Expected runtime error. RuntimeErrors: %+v Crashes: %+v", output.RuntimeErrors, output.Crashes)
+	}
+}
+
+func TestPassingPackageDoesNotCrash(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	packageOutput := func(output string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Output: output + "\n"}
+	}
+
+	// A test prints a panic that it recovers from. The package goes on to pass.
+	output, err := Parse(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "run", Package: pkg, Test: "TestRecover"},
+		packageOutput("panic: close of closed channel"),
+		packageOutput("goroutine 7 [running]:"),
+		TestLogLine{Action: "pass", Package: pkg, Test: "TestRecover"},
+		packageOutput("ok  \t"+pkg+"\t0.012s"),
+		TestLogLine{Action: "pass", Package: pkg, Elapsed: 0.012},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Crashes) != 0 || len(output.RuntimeErrors) != 0 || len(output.TestFailures) != 0 {
+		t.Fatalf("Expected no failures. Crashes: %+v RuntimeErrors: %+v TestFailures: %v",
+			output.Crashes, output.RuntimeErrors, output.TestFailures)
+	}
+}
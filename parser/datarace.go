@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A single `WARNING: DATA RACE` report from the race detector.
+type Datarace struct {
+	// The conflicting pair of memory accesses. The current access is first and the previous access
+	// is second.
+	Accesses []RaceAccess
+	// Where the goroutines involved in the race were created.
+	Creations []GoroutineCreation
+}
+
+type RaceAccess struct {
+	// E.g: `Read`, `Write` or `Previous write`.
+	Kind        string
+	Address     string
+	GoroutineID int
+	Frames      []StackFrame
+}
+
+type GoroutineCreation struct {
+	GoroutineID int
+	// E.g: `running` or `finished`.
+	State  string
+	Frames []StackFrame
+}
+
+// E.g: "Read at 0x00c01020f083 by goroutine 5774:"
+// E.g: "Previous write at 0x00c01020f083 by main goroutine:"
+var raceAccessRe *regexp.Regexp = regexp.MustCompile(
+	`^((?:Previous )?(?:[Aa]tomic )?(?:[Rr]ead|[Ww]rite)) at (0x[0-9a-f]+) by (?:goroutine (\d+)|main goroutine):$`)
+
+// E.g: "Goroutine 5774 (running) created at:"
+var raceCreationRe *regexp.Regexp = regexp.MustCompile(
+	`^Goroutine (\d+) \((\w+)\) created at:$`)
+
+// The line that opens and closes each data race report.
+const raceReportDelimiter = "=================="
+
+// Parses the data race reports out of race detector result. E.g:
+//
+//	WARNING: DATA RACE
+//	Read at 0x00c01020f083 by goroutine 5774:
+//	  go.viam.com/rdk/motionplan.(*cBiRRTMotionPlanner).rrtBackgroundRunner()
+//	      /__w/rdk/rdk/motionplan/cBiRRT.go:197 +0x10d4
+//
+//	Previous write at 0x00c01020f083 by goroutine 5666:
+//	  testing.tRunner.func1()
+//	      /usr/lib/go-1.19/src/testing/testing.go:1433 +0x554
+//
+//	Goroutine 5774 (running) created at:
+//	  go.viam.com/utils.PanicCapturingGoWithCallback()
+//	      /home/testbot/go/pkg/mod/go.viam.com/utils@v0.1.38/runtime.go:151 +0xc4
+//	==================
+func parseDataraces(lines []string, ownModulePrefixes []string) []*Datarace {
+	ret := make([]*Datarace, 0)
+
+	var race *Datarace
+	// The stack currently being parsed.
+	var frames *[]StackFrame
+	var pendingFunc string
+	for _, line := range lines {
+		switch {
+		case line == "WARNING: DATA RACE":
+			race = &Datarace{}
+			ret = append(ret, race)
+			frames, pendingFunc = nil, ""
+			continue
+		case line == raceReportDelimiter:
+			race, frames, pendingFunc = nil, nil, ""
+			continue
+		case race == nil:
+			continue
+		}
+
+		if matches := raceAccessRe.FindStringSubmatch(line); len(matches) > 0 {
+			// The main goroutine is goroutine 1.
+			goroutineID := 1
+			if matches[3] != "" {
+				goroutineID = atoi(matches[3])
+			}
+			race.Accesses = append(race.Accesses, RaceAccess{
+				Kind:        matches[1],
+				Address:     matches[2],
+				GoroutineID: goroutineID,
+			})
+			frames, pendingFunc = &race.Accesses[len(race.Accesses)-1].Frames, ""
+			continue
+		}
+
+		if matches := raceCreationRe.FindStringSubmatch(line); len(matches) > 0 {
+			race.Creations = append(race.Creations, GoroutineCreation{
+				GoroutineID: atoi(matches[1]),
+				State:       matches[2],
+			})
+			frames, pendingFunc = &race.Creations[len(race.Creations)-1].Frames, ""
+			continue
+		}
+
+		if frames == nil {
+			continue
+		}
+
+		if pendingFunc != "" {
+			if file, lineNum, ok := parseFrameFileLine(line); ok {
+				*frames = append(*frames, newStackFrame(pendingFunc, file, lineNum, ownModulePrefixes))
+				pendingFunc = ""
+				continue
+			}
+		}
+
+		if funcName, ok := parseFrameFunc(line); ok {
+			pendingFunc = funcName
+		}
+	}
+
+	return ret
+}
+
+// Returns the top frame of the access in our own code. Falls back to the top frame if no frame
+// is in our own code. Returns nil for an access without a stack.
+func (access RaceAccess) TopFrame() *StackFrame {
+	for idx := range access.Frames {
+		if access.Frames[idx].OwnCode {
+			return &access.Frames[idx]
+		}
+	}
+
+	if len(access.Frames) > 0 {
+		return &access.Frames[0]
+	}
+
+	return nil
+}
+
+// Identifies a race by the functions of the top in-repo frames of both accesses. The same race
+// reported by different tests (or with different line numbers) has the same signature. E.g:
+// `go.viam.com/rdk/motionplan.(*cBiRRTMotionPlanner).rrtBackgroundRunner <-> testing.tRunner.func1`
+func (race *Datarace) Signature() string {
+	funcs := make([]string, 0, len(race.Accesses))
+	for _, access := range race.Accesses {
+		if frame := access.TopFrame(); frame != nil {
+			funcs = append(funcs, frame.Func)
+		}
+	}
+	sort.Strings(funcs)
+
+	return strings.Join(funcs, " <-> ")
+}
+
+// E.g:
+//
+//	Read at 0x00c01020f083 by goroutine 5774:
+//	  go.viam.com/rdk/motionplan.(*cBiRRTMotionPlanner).rrtBackgroundRunner cBiRRT.go:197
+//	Previous write at 0x00c01020f083 by goroutine 5666:
+//	  testing.tRunner.func1 testing.go:1433
+//	Datarace signature: go.viam.com/rdk/motionplan.(*cBiRRTMotionPlanner).rrtBackgroundRunner <-> testing.tRunner.func1
+func (race *Datarace) ToPrettyString(indent string) string {
+	lines := make([]string, 0)
+	for _, access := range race.Accesses {
+		lines = append(lines, fmt.Sprintf("%s%v at %v by goroutine %d:",
+			indent, access.Kind, access.Address, access.GoroutineID))
+		if frame := access.TopFrame(); frame != nil {
+			lines = append(lines, fmt.Sprintf("%s  %v", indent, frame.ToPrettyString()))
+		}
+	}
+	if signature := race.Signature(); signature != "" {
+		lines = append(lines, fmt.Sprintf("%s%v%v", indent, dataraceSignaturePrefix, signature))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Prefixes a race's signature in ticket descriptions.
+const dataraceSignaturePrefix = "Datarace signature: "
+
+// Fills in the `Races` from the failure's log lines.
+func (failure *DataraceFailure) parseLogLines(ownModulePrefixes []string) {
+	failure.Races = parseDataraces(failure.LogLines, ownModulePrefixes)
+}
+
+// Returns the signature line of each race. E.g: `Datarace signature: <signature>`. Races without
+// any parsed access frames have no signature. A bare prefix would match every race ticket.
+func (failure *DataraceFailure) Signatures() []string {
+	ret := make([]string, 0, len(failure.Races))
+	for _, race := range failure.Races {
+		if signature := race.Signature(); signature != "" {
+			ret = append(ret, dataraceSignaturePrefix+signature)
+		}
+	}
+
+	return ret
+}
+
+func (failure *DataraceFailure) ToPrettyString(indent string) string {
+	parts := make([]string, 0, len(failure.Races))
+	for _, race := range failure.Races {
+		parts = append(parts, race.ToPrettyString(indent))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
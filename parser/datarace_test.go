@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestDataraceSignature(t *testing.T) {
+	raceReport := func(readLine int) []string {
+		return strings.Split(fmt.Sprintf(`WARNING: DATA RACE
+Read at 0x00c01020f083 by goroutine 5774:
+  testing.(*common).logDepth()
+      /usr/lib/go-1.19/src/testing/testing.go:883 +0x7c
+  go.viam.com/rdk/motionplan.(*cBiRRTMotionPlanner).rrtBackgroundRunner()
+      /__w/rdk/rdk/motionplan/cBiRRT.go:%d +0x10d4
+
+Previous write at 0x00c01020f083 by main goroutine:
+  testing.tRunner.func1()
+      /usr/lib/go-1.19/src/testing/testing.go:1433 +0x554
+
+Goroutine 5774 (running) created at:
+  go.viam.com/utils.PanicCapturingGoWithCallback()
+      /home/testbot/go/pkg/mod/go.viam.com/utils@v0.1.38/runtime.go:151 +0xc4
+==================`, readLine), "\n")
+	}
+
+	races := parseDataraces(raceReport(197), DefaultOwnModulePrefixes)
+	if len(races) != 1 {
+		t.Fatalf("Wrong number of races: %v", len(races))
+	}
+
+	race := races[0]
+	if len(race.Accesses) != 2 || len(race.Creations) != 1 {
+		t.Fatalf("Wrong race: %+v", race)
+	}
+	if access := race.Accesses[1]; access.Kind != "Previous write" || access.GoroutineID != 1 {
+		t.Fatalf("Wrong previous access: %+v", access)
+	}
+	if frame := race.Accesses[0].TopFrame(); frame.File != "/__w/rdk/rdk/motionplan/cBiRRT.go" || frame.Line != 197 {
+		t.Fatalf("Wrong top frame: %+v", frame)
+	}
+
+	expectedSignature := "go.viam.com/rdk/motionplan.(*cBiRRTMotionPlanner).rrtBackgroundRunner <-> testing.tRunner.func1"
+	if signature := race.Signature(); signature != expectedSignature {
+		t.Fatalf("Wrong signature: %v", signature)
+	}
+
+	// The same race at a different line has the same signature.
+	if signature := parseDataraces(raceReport(205), DefaultOwnModulePrefixes)[0].Signature(); signature != expectedSignature {
+		t.Fatalf("Wrong signature: %v", signature)
+	}
+}
+
+// A race report cut off before the stacks has no signature. See `DataraceFailure.Signatures`.
+func TestDataraceWithoutAccesses(t *testing.T) {
+	failure := &DataraceFailure{
+		Package:  "go.viam.com/rdk/motionplan",
+		LogLines: []string{"WARNING: DATA RACE", "=================="},
+	}
+	failure.parseLogLines(DefaultOwnModulePrefixes)
+	if len(failure.Races) != 1 || failure.Races[0].Signature() != "" {
+		t.Fatalf("Wrong races: %+v", failure.Races)
+	}
+	if signatures := failure.Signatures(); len(signatures) != 0 {
+		t.Fatalf("Expected no signatures. Actual: %q", signatures)
+	}
+	if pretty := failure.ToPrettyString(""); strings.Contains(pretty, dataraceSignaturePrefix) {
+		t.Fatalf("Expected no signature line. Actual: %q", pretty)
+	}
+
+	leak := &LeakFailure{Goroutines: []*Goroutine{{ID: 7, Frames: []StackFrame{{File: "runtime/proc.go", Line: 398}}}}}
+	if signatures := leak.Signatures(); len(signatures) != 0 {
+		t.Fatalf("Expected no leak signatures. Actual: %q", signatures)
+	}
+}
+
+func TestDataraceAttribution(t *testing.T) {
+	const pkg = "go.viam.com/rdk/services/motion/builtin"
+	packageOutput := func(output string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Output: output + "\n"}
+	}
+	testOutput := func(test, output string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: output + "\n"}
+	}
+
+	output, err := Parse(context.Background(), parsertest.LogReader(
+		testOutput("TestMoveOnGlobe", "=== RUN   TestMoveOnGlobe"),
+		packageOutput("=================="),
+		packageOutput("WARNING: DATA RACE"),
+		packageOutput("Read at 0x00c01020f083 by goroutine 5774:"),
+		// Race report lines may be interleaved with output attributed to other tests.
+		testOutput("TestMoveOnGlobe", "  go.viam.com/rdk/motionplan.(*cBiRRTMotionPlanner).rrtBackgroundRunner()"),
+		packageOutput("      /__w/rdk/rdk/motionplan/cBiRRT.go:197 +0x10d4"),
+		packageOutput(""),
+		packageOutput("Previous write at 0x00c01020f083 by goroutine 5666:"),
+		packageOutput("  testing.tRunner.func1()"),
+		packageOutput("      /usr/lib/go-1.19/src/testing/testing.go:1433 +0x554"),
+		packageOutput("=================="),
+		testOutput("TestMoveOnGlobe", "    testing.go:1465: race detected during execution of test"),
+		testOutput("TestMoveOnGlobe", "--- FAIL: TestMoveOnGlobe (0.00s)"),
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestMoveOnGlobe"},
+		packageOutput("Found 1 data race(s)"),
+		packageOutput("FAIL\t"+pkg+"\t80.534s"),
+		TestLogLine{Action: "fail", Package: pkg},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const fqTest = FQTest(pkg + ".TestMoveOnGlobe")
+	if len(output.Dataraces) != 1 {
+		t.Fatalf("Wrong dataraces: %+v", output.Dataraces)
+	}
+	datarace, exists := output.Dataraces[fqTest]
+	if !exists {
+		t.Fatalf("Expected a datarace for %v. Dataraces: %+v", fqTest, output.Dataraces)
+	}
+	if datarace.Package != pkg || datarace.Test != "TestMoveOnGlobe" {
+		t.Fatalf("Wrong datarace: %+v", datarace)
+	}
+	if len(datarace.Races) != 1 || len(datarace.Races[0].Accesses) != 2 ||
+		len(datarace.Races[0].Accesses[0].Frames) != 1 {
+		t.Fatalf("Wrong races: %+v", datarace.Races)
+	}
+	if len(output.UnknownFailures) != 0 {
+		t.Fatalf("Unexpected unknown failures: %+v", output.UnknownFailures)
+	}
+}
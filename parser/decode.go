@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// How well a test log parsed. A test2json stream can contain lines that are not test2json, e.g:
+// `go: downloading ...` build chatter, or end with a truncated record when the job was cancelled.
+type Diagnostics struct {
+	NumLines int
+	// Lines that are not JSON. They are kept as `UntaggedOutput`.
+	NumUntaggedLines int
+	// Lines that look like JSON but do not decode, other than a truncated final record. They are
+	// also kept as `UntaggedOutput`.
+	NumMalformedLines int
+	// True if the final record was cut off.
+	TruncatedTail bool
+}
+
+// Returns the number of lines that were not parsed as test2json records.
+func (diagnostics Diagnostics) NumSkippedLines() int {
+	ret := diagnostics.NumUntaggedLines + diagnostics.NumMalformedLines
+	if diagnostics.TruncatedTail {
+		ret++
+	}
+
+	return ret
+}
+
+// E.g: "Skipped 3 of 12345 lines (2 untagged, 0 malformed, truncated final record)"
+func (diagnostics Diagnostics) ToPrettyString() string {
+	ret := fmt.Sprintf("Skipped %d of %d lines (%d untagged, %d malformed",
+		diagnostics.NumSkippedLines(), diagnostics.NumLines,
+		diagnostics.NumUntaggedLines, diagnostics.NumMalformedLines)
+	if diagnostics.TruncatedTail {
+		ret += ", truncated final record"
+	}
+
+	return ret + ")"
+}
+
+// A stream of test2json records.
+type testLogSource interface {
+	// Advances to the next record. Returns false at the end of the stream.
+	Next() bool
+	Doc() TestLogLine
+	// Returns the error that stopped the stream, if any.
+	Err() error
+	Diagnostics() Diagnostics
+	// Lines that are not test2json records.
+	UntaggedOutput() []string
+}
+
+// Reads test2json records one line at a time. Unlike a `json.Decoder`, a line that does not
+// decode does not end the stream.
+type logLineReader struct {
+	reader *bufio.Reader
+	doc    TestLogLine
+	err    error
+	// The line after the current one. A malformed record is only a truncated tail if it is the
+	// last line.
+	next   string
+	hasEOF bool
+
+	diagnostics    Diagnostics
+	untaggedOutput []string
+}
+
+func newLogLineReader(logContents io.Reader) *logLineReader {
+	ret := &logLineReader{reader: bufio.NewReader(logContents)}
+	ret.next, ret.hasEOF = ret.readLine()
+	return ret
+}
+
+// Returns the next line, without its line ending, and whether the stream has ended.
+func (lines *logLineReader) readLine() (string, bool) {
+	line, err := lines.reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		lines.err = err
+		return "", true
+	}
+
+	return strings.TrimRight(line, "\r\n"), err != nil
+}
+
+// Advances to the next test2json record. Returns false at the end of the stream or on a read
+// error. See `Err`.
+func (lines *logLineReader) Next() bool {
+	for lines.err == nil {
+		line, isLast := lines.next, lines.hasEOF
+		if isLast && line == "" {
+			return false
+		}
+		lines.next, lines.hasEOF = "", true
+		if !isLast {
+			lines.next, lines.hasEOF = lines.readLine()
+		}
+		// A trailing newline leaves an empty final line.
+		isLast = isLast || (lines.hasEOF && lines.next == "")
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines.diagnostics.NumLines++
+
+		if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+			lines.diagnostics.NumUntaggedLines++
+			lines.untaggedOutput = append(lines.untaggedOutput, line)
+			continue
+		}
+
+		doc := TestLogLine{}
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			if isLast {
+				lines.diagnostics.TruncatedTail = true
+				return false
+			}
+
+			lines.diagnostics.NumMalformedLines++
+			lines.untaggedOutput = append(lines.untaggedOutput, line)
+			continue
+		}
+
+		lines.doc = doc
+		return true
+	}
+
+	return false
+}
+
+// The current record.
+func (lines *logLineReader) Doc() TestLogLine {
+	return lines.doc
+}
+
+// Returns the error that stopped reading, if any. Lines that do not decode are not errors.
+func (lines *logLineReader) Err() error {
+	return lines.err
+}
+
+func (lines *logLineReader) Diagnostics() Diagnostics {
+	return lines.diagnostics
+}
+
+func (lines *logLineReader) UntaggedOutput() []string {
+	return lines.untaggedOutput
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTolerantDecoding(t *testing.T) {
+	const pkg = "go.viam.com/rdk/foo"
+	stream := strings.Join([]string{
+		"go: downloading go.viam.com/utils v0.1.38",
+		`{"Action":"run","Package":"go.viam.com/rdk/foo","Test":"TestFoo"}`,
+		`{"Action":"output","Package":"go.viam.com/rdk/foo","Test":"TestFoo","Output":"    foo_test.go:12: Expected: nil\n"}`,
+		`{"Action":"output","Package":"go.viam.com/rdk/foo","Test":"TestFoo","Outp`,
+		`{"Action":"output","Package":"go.viam.com/rdk/foo","Test":"TestFoo","Output":"        Actual:   'boom'\n"}`,
+		"",
+		`{"Action":"fail","Package":"go.viam.com/rdk/foo","Test":"TestFoo"}`,
+		// The job was cancelled mid-write.
+		`{"Action":"output","Package":"go.viam.com/rdk/foo","Output":"FAIL\tgo.viam`,
+	}, "\n")
+
+	output, err := Parse(context.Background(), strings.NewReader(stream), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fqTest := FQTest(pkg + ".TestFoo")
+	if assertions := output.Assertions[fqTest]; len(assertions) != 1 || assertions[0].Actual != "   'boom'" {
+		t.Fatalf("Expected the assertion to survive the bad lines. Assertions: %v", output.Assertions)
+	}
+
+	expected := Diagnostics{NumLines: 7, NumUntaggedLines: 1, NumMalformedLines: 1, TruncatedTail: true}
+	if output.Diagnostics != expected {
+		t.Fatalf("Wrong diagnostics. Expected: %+v Actual: %+v", expected, output.Diagnostics)
+	}
+	if output.Diagnostics.NumSkippedLines() != 3 || len(output.UntaggedOutput) != 2 ||
+		output.UntaggedOutput[0] != "go: downloading go.viam.com/utils v0.1.38" {
+		t.Fatalf("Wrong untagged output: %v", output.UntaggedOutput)
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A test that failed and then passed when rerun. E.g: with `gotestsum --rerun-fails`, the test2json
+// stream contains the failed attempt followed by the passing rerun of the same test.
+type Flake struct {
+	Package string
+	Name    string
+	// The number of times the test ran, including the passing rerun.
+	Attempts int
+}
+
+func (flake *Flake) ToFQTest() FQTest {
+	return TestLogLine{Package: flake.Package, Test: flake.Name}.ToFQTest()
+}
+
+// Which rerun passed. The first run is not a rerun.
+func (flake *Flake) PassedOnRerun() int {
+	return flake.Attempts - 1
+}
+
+// E.g: "flaked, passed on rerun 2"
+func (flake *Flake) ToPrettyString() string {
+	return fmt.Sprintf("flaked, passed on rerun %d", flake.PassedOnRerun())
+}
+
+// Moves the test failures that passed on a later run from `TestFailures` to `Flakes`. The flakes'
+// failure details (e.g: their `Assertions`) are kept for filing tickets. Package failures of
+// packages that passed on a later run are dropped.
+func (result *Result) separateRerunFlakes() {
+	hardFailures := make([]FQTest, 0, len(result.TestFailures))
+	for _, testFailure := range result.TestFailures {
+		record := result.Tests[testFailure]
+		if record == nil || record.Runs < 2 || record.Status != "pass" {
+			hardFailures = append(hardFailures, testFailure)
+			continue
+		}
+
+		result.Flakes[testFailure] = &Flake{
+			Package:  record.Package,
+			Name:     record.Name,
+			Attempts: record.Runs,
+		}
+	}
+	result.TestFailures = hardFailures
+
+	packageFailures := make([]TestLogLine, 0, len(result.PackageFailures))
+	for _, packageFailure := range result.PackageFailures {
+		if record := result.Packages[packageFailure.Package]; record != nil && record.Status == "pass" {
+			continue
+		}
+		packageFailures = append(packageFailures, packageFailure)
+	}
+	result.PackageFailures = packageFailures
+}
+
+// Returns the number of failures that are not flakes.
+func numHardFailures[V any](failures map[FQTest]V, flakes map[FQTest]*Flake) int {
+	ret := 0
+	for fqTest := range failures {
+		if _, exists := flakes[fqTest]; !exists {
+			ret++
+		}
+	}
+
+	return ret
+}
+
+// Returns the test failures and the flakes, sorted. Tickets are filed for both.
+func (result *Result) FailuresToTicket() []FQTest {
+	ret := append([]FQTest{}, result.TestFailures...)
+	for fqTest := range result.Flakes {
+		ret = append(ret, fqTest)
+	}
+	sort.Slice(ret, func(left, right int) bool {
+		return ret[left] < ret[right]
+	})
+
+	return ret
+}
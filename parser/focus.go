@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The most focus lines returned. The first failure is the most interesting one.
+const maxFocusLines = 3
+
+// E.g: "--- FAIL: TestFoo (1.23s)"
+var failLineRe *regexp.Regexp = regexp.MustCompile(
+	`^\s*--- FAIL: `)
+
+// Returns the indexes of the lines where the failure is reported, e.g: an assertion, a panic or
+// a data race. At most `maxFocusLines` are returned. Excerpts of the logs are centred on them.
+func FocusLines(logs []string) []int {
+	ret := make([]int, 0)
+	for idx, line := range logs {
+		if len(ret) == maxFocusLines {
+			break
+		}
+
+		if expectedRe.MatchString(line) || startCrashRe.MatchString(line) ||
+			startTimeoutRe.MatchString(line) || line == "WARNING: DATA RACE" ||
+			strings.Contains(line, leakStartMarker) {
+			ret = append(ret, idx)
+		}
+	}
+
+	// A plain `--- FAIL` is only interesting if nothing better was found.
+	if len(ret) == 0 {
+		for idx, line := range logs {
+			if failLineRe.MatchString(line) {
+				return []int{idx}
+			}
+		}
+	}
+
+	return ret
+}
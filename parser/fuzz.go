@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A `Fuzz*` test that found a failing input. E.g:
+//
+//	--- FAIL: FuzzParseFrame (0.04s)
+//	    --- FAIL: FuzzParseFrame (0.00s)
+//	        frame_test.go:20: unexpected error: invalid frame
+//
+//	    Failing input written to testdata/fuzz/FuzzParseFrame/af69258a12129d6c
+//	    To re-run:
+//	    go test -run=FuzzParseFrame/af69258a12129d6c
+type FuzzFailure struct {
+	Package string
+	// E.g: `FuzzParseFrame`.
+	Test string
+	// E.g: `af69258a12129d6c`.
+	Hash string
+	// The contents of the corpus file, if they were printed to the logs. E.g:
+	//
+	//	go test fuzz v1
+	//	[]byte("\x9c\xdd")
+	Corpus []string
+}
+
+// E.g: "    Failing input written to testdata/fuzz/FuzzParseFrame/af69258a12129d6c"
+var fuzzInputWrittenRe *regexp.Regexp = regexp.MustCompile(
+	`^\s*Failing input written to testdata/fuzz/(Fuzz[^/\s]*)/(\S+)$`)
+
+// The first line of every fuzz corpus file.
+const fuzzCorpusHeader = "go test fuzz v1"
+
+// E.g: `[]byte("\x9c\xdd")`, `string("foo")`, `int64(-3)` or `bool(true)`.
+var fuzzCorpusValueRe *regexp.Regexp = regexp.MustCompile(
+	`^(?:\[\]byte|string|bool|byte|rune|u?int(?:8|16|32|64)?|float(?:32|64))\(.*\)$`)
+
+func newFuzzFailure(doc TestLogLine) *FuzzFailure {
+	matches := fuzzInputWrittenRe.FindStringSubmatch(doc.Output)
+	return &FuzzFailure{
+		Package: doc.Package,
+		Test:    matches[1],
+		Hash:    matches[2],
+	}
+}
+
+func (fuzz *FuzzFailure) ToFQTest() FQTest {
+	return TestLogLine{Package: fuzz.Package, Test: fuzz.Test}.ToFQTest()
+}
+
+// Finds the failing input's corpus file in the log lines. Go does not print the failing input
+// itself, but CI can, e.g: with `cat testdata/fuzz/FuzzX/*`.
+func (fuzz *FuzzFailure) parseLogLines(lines []string) {
+	for idx, line := range lines {
+		if strings.TrimSpace(line) != fuzzCorpusHeader {
+			continue
+		}
+
+		corpus := []string{fuzzCorpusHeader}
+		for _, valueLine := range lines[idx+1:] {
+			valueLine = strings.TrimSpace(valueLine)
+			if !fuzzCorpusValueRe.MatchString(valueLine) {
+				break
+			}
+			corpus = append(corpus, valueLine)
+		}
+		if len(corpus) > 1 {
+			fuzz.Corpus = corpus
+		}
+	}
+}
+
+// E.g: `testdata/fuzz/FuzzParseFrame/af69258a12129d6c`, relative to the package's directory.
+func (fuzz *FuzzFailure) CorpusPath() string {
+	return fmt.Sprintf("testdata/fuzz/%v/%v", fuzz.Test, fuzz.Hash)
+}
+
+// E.g: `go test -run=FuzzParseFrame/af69258a12129d6c go.viam.com/rdk/referenceframe`
+func (fuzz *FuzzFailure) ReproduceCommand() string {
+	return fmt.Sprintf("go test -run=%v/%v %v", fuzz.Test, fuzz.Hash, fuzz.Package)
+}
+
+// E.g:
+//
+//	Failing input written to testdata/fuzz/FuzzParseFrame/af69258a12129d6c
+//	To re-run:
+//	go test -run=FuzzParseFrame/af69258a12129d6c go.viam.com/rdk/referenceframe
+func (fuzz *FuzzFailure) ToPrettyString(indent string) string {
+	lines := []string{
+		fmt.Sprintf("%sFailing input written to %v", indent, fuzz.CorpusPath()),
+		indent + "To re-run:",
+		indent + fuzz.ReproduceCommand(),
+	}
+	if len(fuzz.Corpus) == 0 {
+		lines = append(lines, indent+"The failing input was not found in the logs.")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestFuzzFailure(t *testing.T) {
+	const pkg = "go.viam.com/rdk/referenceframe"
+	const hash = "af69258a12129d6cbba438df5d5f25ba0ec050461c116f777e77ea7c9a0d217a"
+	output := func(test, line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+
+	logs, err := Parse(context.Background(), parsertest.LogReader(
+		output("FuzzParseFrame", "=== RUN   FuzzParseFrame"),
+		output("FuzzParseFrame", "--- FAIL: FuzzParseFrame (0.04s)"),
+		output("FuzzParseFrame", "    --- FAIL: FuzzParseFrame (0.00s)"),
+		output("FuzzParseFrame", "        frame_test.go:20: unexpected error: invalid frame"),
+		output("FuzzParseFrame", ""),
+		output("FuzzParseFrame", "    Failing input written to testdata/fuzz/FuzzParseFrame/"+hash),
+		output("FuzzParseFrame", "    To re-run:"),
+		output("FuzzParseFrame", "    go test -run=FuzzParseFrame/"+hash),
+		TestLogLine{Action: "fail", Package: pkg, Test: "FuzzParseFrame"},
+		output("", "FAIL"),
+		output("", "go test fuzz v1"),
+		output("", `[]byte("\x9c\xdd")`),
+		output("", "int64(-3)"),
+		output("", "FAIL\tgo.viam.com/rdk/referenceframe\t0.1s"),
+		TestLogLine{Action: "fail", Package: pkg},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fqTest := FQTest(pkg + ".FuzzParseFrame")
+	fuzz := logs.Fuzz[fqTest]
+	if fuzz == nil || fuzz.Hash != hash {
+		t.Fatalf("Expected a fuzz failure. Actual: %+v", logs.Fuzz)
+	}
+	if expected := "go test -run=FuzzParseFrame/" + hash + " " + pkg; fuzz.ReproduceCommand() != expected {
+		t.Fatalf("Wrong reproduce command. Expected: `%v` Actual: `%v`", expected, fuzz.ReproduceCommand())
+	}
+	if len(fuzz.Corpus) != 3 || fuzz.Corpus[1] != `[]byte("\x9c\xdd")` || fuzz.Corpus[2] != "int64(-3)" {
+		t.Fatalf("Wrong corpus: %v", fuzz.Corpus)
+	}
+
+	if len(logs.TestFailures) != 1 || logs.TestFailures[0] != fqTest || len(logs.UnknownFailures) != 0 {
+		t.Fatalf("Expected only the fuzz test to fail. Failures: %v", logs.TestFailures)
+	}
+}
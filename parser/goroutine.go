@@ -0,0 +1,287 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A goroutine parsed out of a go stack dump. E.g:
+//
+//	goroutine 64 [chan receive, 9 minutes]:
+//	testing.(*T).Run(0xc000282ea0, {0x299c94d, 0x28}, 0xc000776140)
+//		/usr/lib/go-1.19/src/testing/testing.go:1494 +0x789
+//	created by testing.(*T).Run
+//		/usr/lib/go-1.19/src/testing/testing.go:1493 +0x75e
+type Goroutine struct {
+	ID int
+	// The wait reason. E.g: `running`, `select` or `chan receive`.
+	State string
+	// How long the goroutine has been blocked. Go only prints this for goroutines blocked for at
+	// least a minute.
+	Minutes   int
+	Frames    []StackFrame
+	CreatedBy *StackFrame
+}
+
+type StackFrame struct {
+	// E.g: `go.viam.com/rdk/services/navigation/builtin.(*builtIn).startWaypoint.func1`
+	Func string
+	File string
+	Line int
+	// Set if the function is in one of our own modules. See `Options.OwnModulePrefixes`.
+	OwnCode bool
+}
+
+// E.g: "goroutine 64 [chan receive, 9 minutes]:"
+var goroutineHeaderRe *regexp.Regexp = regexp.MustCompile(
+	`^goroutine (\d+) \[(.+)\]:$`)
+
+// E.g: "9 minutes"
+var goroutineMinutesRe *regexp.Regexp = regexp.MustCompile(
+	`^(\d+) minutes?$`)
+
+// E.g: "\t/__w/rdk/rdk/services/navigation/builtin/builtin.go:301 +0x373"
+var stackFileLineRe *regexp.Regexp = regexp.MustCompile(
+	`^(\S+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+
+// Returns the function name of a stack frame's function line. E.g:
+// `testing.(*T).Run(0xc000282ea0, {0x299c94d, 0x28})` returns `testing.(*T).Run`. Returns false
+// if the line does not look like a function line.
+func parseFrameFunc(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasSuffix(line, ")") {
+		return "", false
+	}
+
+	// Walk backwards to the parenthesis that opens the argument list.
+	depth := 0
+	for idx := len(line) - 1; idx >= 0; idx-- {
+		switch line[idx] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+		}
+
+		if depth == 0 {
+			name := line[:idx]
+			if name == "" || strings.ContainsAny(name, " \t") {
+				return "", false
+			}
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// Parses a stack frame's file line. E.g: `/__w/rdk/rdk/motionplan/cBiRRT.go:197 +0x10d4`.
+func parseFrameFileLine(line string) (string, int, bool) {
+	matches := stackFileLineRe.FindStringSubmatch(strings.TrimSpace(line))
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	return matches[1], atoi(matches[2]), true
+}
+
+// Parses the goroutines out of a go stack dump, e.g: as printed on a panic or test timeout. Lines
+// that are not part of a goroutine stack are ignored, as test output can interleave with the dump.
+func parseGoroutineDump(lines []string, ownModulePrefixes []string) []*Goroutine {
+	ret := make([]*Goroutine, 0)
+
+	var current *Goroutine
+	// A function line waiting on its file line.
+	var pendingFunc string
+	var pendingCreatedBy bool
+	for _, line := range lines {
+		if matches := goroutineHeaderRe.FindStringSubmatch(line); len(matches) > 0 {
+			current = &Goroutine{ID: atoi(matches[1])}
+			// E.g: "chan receive, 9 minutes, locked to thread"
+			for idx, part := range strings.Split(matches[2], ", ") {
+				if idx == 0 {
+					current.State = part
+					continue
+				}
+
+				if minutes := goroutineMinutesRe.FindStringSubmatch(part); len(minutes) > 0 {
+					current.Minutes = atoi(minutes[1])
+				}
+			}
+			ret = append(ret, current)
+			pendingFunc = ""
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if pendingFunc != "" {
+			if file, lineNum, ok := parseFrameFileLine(line); ok {
+				frame := newStackFrame(pendingFunc, file, lineNum, ownModulePrefixes)
+				if pendingCreatedBy {
+					current.CreatedBy = &frame
+				} else {
+					current.Frames = append(current.Frames, frame)
+				}
+				pendingFunc = ""
+				continue
+			}
+		}
+
+		// E.g: "created by testing.(*T).Run in goroutine 7"
+		if createdBy, found := strings.CutPrefix(strings.TrimSpace(line), "created by "); found {
+			createdBy, _, _ = strings.Cut(createdBy, " in goroutine ")
+			pendingFunc, pendingCreatedBy = createdBy, true
+			continue
+		}
+
+		if funcName, ok := parseFrameFunc(line); ok {
+			pendingFunc, pendingCreatedBy = funcName, false
+		}
+	}
+
+	return ret
+}
+
+// Returns true if the goroutine has a frame with the input function name.
+func (goroutine *Goroutine) HasFrame(funcName string) bool {
+	for _, frame := range goroutine.Frames {
+		if frame.Func == funcName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newStackFrame(funcName, file string, line int, ownModulePrefixes []string) StackFrame {
+	frame := StackFrame{Func: funcName, File: file, Line: line}
+	for _, prefix := range ownModulePrefixes {
+		if strings.HasPrefix(funcName, prefix) {
+			frame.OwnCode = true
+			break
+		}
+	}
+
+	return frame
+}
+
+// E.g: `go.viam.com/rdk/motionplan.(*planManager).planParallelRRTMotion.func1 planManager.go:326`
+func (frame StackFrame) ToPrettyString() string {
+	return fmt.Sprintf("%v %v:%d", frame.Func, filepath.Base(frame.File), frame.Line)
+}
+
+// A group of goroutines with identical stacks, in the style of panicparse.
+type GoroutineBucket struct {
+	State string
+	IDs   []int
+	// The range of minutes the goroutines have been blocked for.
+	MinMinutes int
+	MaxMinutes int
+	Frames     []StackFrame
+	CreatedBy  *StackFrame
+}
+
+// Groups goroutines by their wait reason and stack. Buckets are ordered by the number of
+// goroutines, largest first.
+func bucketGoroutines(goroutines []*Goroutine) []*GoroutineBucket {
+	ret := make([]*GoroutineBucket, 0)
+	buckets := make(map[string]*GoroutineBucket)
+	for _, goroutine := range goroutines {
+		keyParts := []string{goroutine.State}
+		for _, frame := range goroutine.Frames {
+			keyParts = append(keyParts, frame.ToPrettyString())
+		}
+		if goroutine.CreatedBy != nil {
+			keyParts = append(keyParts, "created by "+goroutine.CreatedBy.ToPrettyString())
+		}
+		key := strings.Join(keyParts, "\n")
+
+		bucket, exists := buckets[key]
+		if !exists {
+			bucket = &GoroutineBucket{
+				State:      goroutine.State,
+				MinMinutes: goroutine.Minutes,
+				MaxMinutes: goroutine.Minutes,
+				Frames:     goroutine.Frames,
+				CreatedBy:  goroutine.CreatedBy,
+			}
+			buckets[key] = bucket
+			ret = append(ret, bucket)
+		}
+
+		bucket.IDs = append(bucket.IDs, goroutine.ID)
+		bucket.MinMinutes = min(bucket.MinMinutes, goroutine.Minutes)
+		bucket.MaxMinutes = max(bucket.MaxMinutes, goroutine.Minutes)
+	}
+
+	sort.SliceStable(ret, func(left, right int) bool {
+		return len(ret[left].IDs) > len(ret[right].IDs)
+	})
+
+	return ret
+}
+
+// E.g:
+//
+//	3 goroutines [select, 9 minutes]:
+//	  => go.viam.com/rdk/services/navigation/builtin.(*builtIn).startWaypoint.func1 builtin.go:336
+//	  => go.viam.com/utils.PanicCapturingGoWithCallback.func1 runtime.go:164
+//	     created by go.viam.com/utils.PanicCapturingGoWithCallback runtime.go:151
+//
+// Frames in our own modules are marked with `=>`.
+func (bucket *GoroutineBucket) ToPrettyString(indent string) string {
+	var header strings.Builder
+	switch len(bucket.IDs) {
+	case 1:
+		fmt.Fprintf(&header, "%v1 goroutine [%v", indent, bucket.State)
+	default:
+		fmt.Fprintf(&header, "%v%d goroutines [%v", indent, len(bucket.IDs), bucket.State)
+	}
+	switch {
+	case bucket.MaxMinutes == 0:
+	case bucket.MinMinutes == bucket.MaxMinutes:
+		fmt.Fprintf(&header, ", %d minutes", bucket.MaxMinutes)
+	default:
+		fmt.Fprintf(&header, ", %d-%d minutes", bucket.MinMinutes, bucket.MaxMinutes)
+	}
+	header.WriteString("]:")
+
+	lines := []string{header.String()}
+	for _, frame := range bucket.Frames {
+		marker := "   "
+		if frame.OwnCode {
+			marker = "=> "
+		}
+		lines = append(lines, fmt.Sprintf("%v  %v%v", indent, marker, frame.ToPrettyString()))
+	}
+	if bucket.CreatedBy != nil {
+		lines = append(lines, fmt.Sprintf("%v     created by %v", indent, bucket.CreatedBy.ToPrettyString()))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// The maximum number of goroutine buckets rendered in a goroutine summary.
+const maxSummaryBuckets = 15
+
+// Returns a compact summary of a goroutine dump with goroutines grouped by identical stacks.
+func summarizeGoroutines(goroutines []*Goroutine, indent string) string {
+	buckets := bucketGoroutines(goroutines)
+	parts := make([]string, 0, len(buckets))
+	for idx, bucket := range buckets {
+		if idx == maxSummaryBuckets {
+			parts = append(parts, fmt.Sprintf("%v... %d more goroutine groups",
+				indent, len(buckets)-maxSummaryBuckets))
+			break
+		}
+		parts = append(parts, bucket.ToPrettyString(indent))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBucketGoroutines(t *testing.T) {
+	dump := []string{
+		"goroutine 102 [select, 9 minutes]:",
+		"go.viam.com/rdk/services/navigation/builtin.(*builtIn).startWaypoint.func1()",
+		"\t/__w/rdk/rdk/services/navigation/builtin/builtin.go:336 +0x4b2",
+		"created by go.viam.com/utils.PanicCapturingGoWithCallback in goroutine 64",
+		"\t/home/testbot/go/pkg/mod/go.viam.com/utils@v0.1.38/runtime.go:151 +0xd7",
+		"",
+		"goroutine 103 [select, 7 minutes]:",
+		"go.viam.com/rdk/services/navigation/builtin.(*builtIn).startWaypoint.func1()",
+		"\t/__w/rdk/rdk/services/navigation/builtin/builtin.go:336 +0x4b2",
+		"created by go.viam.com/utils.PanicCapturingGoWithCallback in goroutine 64",
+		"\t/home/testbot/go/pkg/mod/go.viam.com/utils@v0.1.38/runtime.go:151 +0xd7",
+		"",
+		"goroutine 7 [select]:",
+		"go.opencensus.io/stats/view.(*worker).start(0xc00011b000)",
+		"\t/home/testbot/go/pkg/mod/go.opencensus.io@v0.24.0/stats/view/worker.go:292 +0x185",
+	}
+
+	goroutines := parseGoroutineDump(dump, DefaultOwnModulePrefixes)
+	if len(goroutines) != 3 {
+		t.Fatalf("Wrong number of goroutines: %v", len(goroutines))
+	}
+	if goroutines[0].State != "select" || goroutines[0].Minutes != 9 || len(goroutines[0].Frames) != 1 {
+		t.Fatalf("Wrong goroutine: %+v", goroutines[0])
+	}
+	if createdBy := goroutines[0].CreatedBy; createdBy == nil || createdBy.Func != "go.viam.com/utils.PanicCapturingGoWithCallback" {
+		t.Fatalf("Wrong created by: %+v", createdBy)
+	}
+
+	buckets := bucketGoroutines(goroutines)
+	if len(buckets) != 2 || len(buckets[0].IDs) != 2 {
+		t.Fatalf("Wrong buckets: %+v", buckets)
+	}
+
+	summary := summarizeGoroutines(goroutines, "")
+	expectedPrefix := strings.Join([]string{
+		"2 goroutines [select, 7-9 minutes]:",
+		"  => go.viam.com/rdk/services/navigation/builtin.(*builtIn).startWaypoint.func1 builtin.go:336",
+		"     created by go.viam.com/utils.PanicCapturingGoWithCallback runtime.go:151",
+	}, "\n")
+	if !strings.HasPrefix(summary, expectedPrefix) {
+		t.Fatalf("Wrong summary:\n%v", summary)
+	}
+}
+
+func TestOwnModulePrefixes(t *testing.T) {
+	dump := []string{
+		"goroutine 7 [select]:",
+		"go.opencensus.io/stats/view.(*worker).start(0xc00011b000)",
+		"\t/home/testbot/go/pkg/mod/go.opencensus.io@v0.24.0/stats/view/worker.go:292 +0x185",
+		"go.viam.com/rdk/robot/impl.(*localRobot).run()",
+		"\t/__w/rdk/rdk/robot/impl/local_robot.go:123 +0x2c",
+	}
+
+	frames := parseGoroutineDump(dump, DefaultOwnModulePrefixes)[0].Frames
+	if frames[0].OwnCode || !frames[1].OwnCode {
+		t.Fatalf("Wrong own code frames: %+v", frames)
+	}
+
+	frames = parseGoroutineDump(dump, []string{"go.opencensus.io/"})[0].Frames
+	if !frames[0].OwnCode || frames[1].OwnCode {
+		t.Fatalf("Wrong own code frames: %+v", frames)
+	}
+}
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The `--json` report of Jest. Vitest's `json` reporter writes the same format. E.g:
+//
+//	{"numTotalTests": 2, "startTime": 1690920901000, "testResults": [{
+//	  "name": "/home/runner/work/app/app/web/src/login.test.ts", "status": "failed",
+//	  "assertionResults": [{"ancestorTitles": ["Login"], "title": "renders", "status": "failed",
+//	    "duration": 12, "failureMessages": ["Error: expect(received).toBe(expected) ..."]}]}]}
+type jestReport struct {
+	// Milliseconds since the epoch.
+	StartTime   int64            `json:"startTime"`
+	TestResults []jestFileResult `json:"testResults"`
+}
+
+type jestFileResult struct {
+	// The absolute path of the test file.
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Why the file failed outside of any test. E.g: a syntax error.
+	Message          string           `json:"message"`
+	AssertionResults []jestTestResult `json:"assertionResults"`
+}
+
+type jestTestResult struct {
+	// The enclosing `describe` blocks, outermost first.
+	AncestorTitles []string `json:"ancestorTitles"`
+	Title          string   `json:"title"`
+	// E.g: `passed`, `failed`, `pending`, `skipped` or `todo`.
+	Status string `json:"status"`
+	// In milliseconds. Null for tests that did not run.
+	Duration        float64  `json:"duration"`
+	FailureMessages []string `json:"failureMessages"`
+	// Only present with `--testLocationInResults`.
+	Location *struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"location"`
+}
+
+// A failed Jest or Vitest test. The `Package` is the test file relative to the repository root.
+type JSFailure struct {
+	Package string
+	Test    string
+	// Where the assertion failed. E.g: `web/src/login.test.ts` and `12`.
+	File string
+	Line int
+	// The failure message without the stack. E.g: `expect(received).toBe(expected)`.
+	Message  string
+	LogLines []string
+}
+
+// Any prefix of a github runner checkout. E.g: `/home/runner/work/app/app/`.
+var runnerCheckoutRe *regexp.Regexp = regexp.MustCompile(
+	`^.*?/work/[^/]+/[^/]+/`)
+
+// E.g: "    at Object.<anonymous> (/home/runner/work/app/app/web/src/login.test.ts:12:5)"
+// E.g: "    at /home/runner/work/app/app/web/src/login.test.ts:12:5"
+var jsStackFrameRe *regexp.Regexp = regexp.MustCompile(
+	`^\s*at (?:.* \()?(?:file://)?([^\s()]+):(\d+):\d+\)?$`)
+
+// Both reports start with their counts, e.g: `{"numFailedTestSuites":1,...`. A test2json log
+// starts with a record, e.g: `{"Time":...`.
+const jestReportMarker = `"numTotalTests"`
+
+// The most bytes of a file that are looked at to recognize a Jest report.
+const jestSniffSize = 4096
+
+// Returns true if the buffered file is a Jest or Vitest report rather than a test2json log.
+func isJestReport(reader *bufio.Reader) bool {
+	// `Peek` returns fewer bytes (and an error) for files shorter than `jestSniffSize`.
+	head, _ := reader.Peek(jestSniffSize)
+	return strings.Contains(string(head), jestReportMarker)
+}
+
+func repoRelativePath(path string) string {
+	return runnerCheckoutRe.ReplaceAllString(path, "")
+}
+
+// Returns the lines of a failure message. Color codes are removed.
+func jsMessageLines(message string) []string {
+	return strings.Split(ansiColorRe.ReplaceAllString(strings.TrimRight(message, "\n"), ""), "\n")
+}
+
+// Mirrors how `go test` names subtests. E.g: `Login/renders_the_page`.
+func (result jestTestResult) testName() string {
+	parts := append(append([]string{}, result.AncestorTitles...), result.Title)
+	return strings.ReplaceAll(strings.Join(parts, "/"), " ", "_")
+}
+
+func newJSFailure(pkg string, result jestTestResult) *JSFailure {
+	ret := &JSFailure{
+		Package: pkg,
+		Test:    result.testName(),
+		File:    pkg,
+	}
+	if result.Location != nil {
+		ret.Line = result.Location.Line
+	}
+
+	messageLines := make([]string, 0)
+	foundFrame := false
+	for _, message := range result.FailureMessages {
+		for _, line := range jsMessageLines(message) {
+			ret.LogLines = append(ret.LogLines, line)
+			matches := jsStackFrameRe.FindStringSubmatch(line)
+			if len(matches) == 0 {
+				if !foundFrame {
+					messageLines = append(messageLines, line)
+				}
+				continue
+			}
+
+			// Prefer the frame in the test file over e.g: a helper or `node_modules`.
+			file := repoRelativePath(matches[1])
+			if !foundFrame || (file == pkg && ret.File != pkg) {
+				ret.File, ret.Line = file, atoi(matches[2])
+			}
+			foundFrame = true
+		}
+	}
+	ret.Message = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.Join(messageLines, "\n")), "Error:"))
+
+	return ret
+}
+
+func (failure *JSFailure) ToFQTest() FQTest {
+	return TestLogLine{Package: failure.Package, Test: failure.Test}.ToFQTest()
+}
+
+func (failure *JSFailure) ToPrettyString(indent string) string {
+	return fmt.Sprintf("%sFile:     %s:%d\n%sMessage:  %v",
+		indent, failure.File, failure.Line,
+		indent, strings.ReplaceAll(failure.Message, "\n", "\n"+indent+"          "))
+}
+
+// Converts the report into the test2json records `go test -json` would have written for it. Each
+// test file is a package.
+func (report jestReport) toTestLogLines() []TestLogLine {
+	ret := make([]TestLogLine, 0)
+	elapsed := 0.0
+	at := func() string {
+		if report.StartTime == 0 {
+			return ""
+		}
+		start := time.UnixMilli(report.StartTime).UTC()
+		return start.Add(time.Duration(elapsed * float64(time.Second))).Format(time.RFC3339Nano)
+	}
+
+	for _, fileResult := range report.TestResults {
+		pkg := repoRelativePath(fileResult.Name)
+		packageElapsed := 0.0
+		for _, result := range fileResult.AssertionResults {
+			test := result.testName()
+			doc := func(action string, output string) TestLogLine {
+				return TestLogLine{Time: at(), Action: action, Package: pkg, Test: test, Output: output}
+			}
+
+			ret = append(ret, doc("run", ""))
+			ret = append(ret, doc("output", fmt.Sprintf("=== RUN   %v\n", test)))
+			for _, message := range result.FailureMessages {
+				for _, line := range jsMessageLines(message) {
+					ret = append(ret, doc("output", line+"\n"))
+				}
+			}
+
+			testElapsed := result.Duration / 1000
+			elapsed += testElapsed
+			packageElapsed += testElapsed
+			action, status := "pass", "PASS"
+			switch result.Status {
+			case "failed":
+				action, status = "fail", "FAIL"
+			case "pending", "skipped", "todo", "disabled":
+				action, status = "skip", "SKIP"
+			}
+			ret = append(ret, doc("output", fmt.Sprintf("--- %v: %v (%.2fs)\n", status, test, testElapsed)))
+			end := doc(action, "")
+			end.Elapsed = testElapsed
+			ret = append(ret, end)
+		}
+
+		if fileResult.Message != "" && fileResult.Status == "failed" {
+			for _, line := range jsMessageLines(fileResult.Message) {
+				ret = append(ret, TestLogLine{Time: at(), Action: "output", Package: pkg, Output: line + "\n"})
+			}
+		}
+		end := TestLogLine{Time: at(), Action: "pass", Package: pkg, Elapsed: packageElapsed}
+		if fileResult.Status == "failed" {
+			end.Action = "fail"
+		}
+		ret = append(ret, end)
+	}
+
+	return ret
+}
+
+// Parses a Jest or Vitest `--json` report into the same `Result` as a test2json log. Failed tests
+// are `JSFailures` rather than unknown failures.
+func ParseJest(ctx context.Context, reader io.Reader, options Options) (*Result, error) {
+	var report jestReport
+	if err := json.NewDecoder(reader).Decode(&report); err != nil {
+		return nil, fmt.Errorf("Bad Jest report. Err: %w", err)
+	}
+
+	ret, err := parseTestLog(ctx, &testLogSlice{docs: report.toTestLogLines()}, options)
+	if err != nil {
+		return ret, err
+	}
+
+	for _, fileResult := range report.TestResults {
+		pkg := repoRelativePath(fileResult.Name)
+		for _, result := range fileResult.AssertionResults {
+			if result.Status != "failed" {
+				continue
+			}
+
+			jsFailure := newJSFailure(pkg, result)
+			fqTest := jsFailure.ToFQTest()
+			ret.JSFailures[fqTest] = jsFailure
+			delete(ret.UnknownFailures, fqTest)
+		}
+	}
+
+	return ret, nil
+}
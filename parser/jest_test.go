@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseJestReport(t *testing.T) {
+	const report = `{
+  "numFailedTestSuites": 1,
+  "numTotalTests": 3,
+  "startTime": 1690920901000,
+  "success": false,
+  "testResults": [{
+    "name": "/home/runner/work/app/app/web/src/login.test.ts",
+    "status": "failed",
+    "message": "",
+    "assertionResults": [{
+      "ancestorTitles": ["Login"],
+      "title": "renders the form",
+      "status": "failed",
+      "duration": 12,
+      "failureMessages": ["Error: \u001b[2mexpect(\u001b[22mreceived\u001b[2m).toBe(\u001b[22mexpected\u001b[2m)\u001b[22m\n\nExpected: 1\nReceived: 2\n    at assertForm (/home/runner/work/app/app/web/src/helpers.ts:7:3)\n    at Object.<anonymous> (/home/runner/work/app/app/web/src/login.test.ts:12:5)"]
+    }, {
+      "ancestorTitles": ["Login"],
+      "title": "submits",
+      "status": "passed",
+      "duration": 3,
+      "failureMessages": []
+    }, {
+      "ancestorTitles": [],
+      "title": "todo later",
+      "status": "todo",
+      "duration": null,
+      "failureMessages": []
+    }]
+  }]
+}`
+
+	reader := bufio.NewReader(strings.NewReader(report))
+	if !isJestReport(reader) {
+		t.Fatal("Expected a Jest report.")
+	}
+	if isJestReport(bufio.NewReader(strings.NewReader(`{"Time":"2023-08-01T20:15:01Z","Action":"run"}`))) {
+		t.Fatal("Expected a test2json log to not be a Jest report.")
+	}
+
+	logs, err := ParseJest(context.Background(), reader, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fqTest := FQTest("web/src/login.test.ts.Login/renders_the_form")
+	if len(logs.TestFailures) != 1 || logs.TestFailures[0] != fqTest {
+		t.Fatalf("Wrong failures: %v", logs.TestFailures)
+	}
+	if len(logs.UnknownFailures) != 0 {
+		t.Fatalf("Expected no unknown failures. Actual: %v", logs.UnknownFailures)
+	}
+
+	jsFailure := logs.JSFailures[fqTest]
+	if jsFailure == nil {
+		t.Fatalf("Expected a JS failure. Actual: %v", logs.JSFailures)
+	}
+	// The frame in the test file wins over the helper that asserted.
+	if jsFailure.File != "web/src/login.test.ts" || jsFailure.Line != 12 {
+		t.Fatalf("Wrong location: %v:%d", jsFailure.File, jsFailure.Line)
+	}
+	if !strings.HasPrefix(jsFailure.Message, "expect(received).toBe(expected)") ||
+		strings.Contains(jsFailure.Message, " at ") {
+		t.Fatalf("Wrong message: %q", jsFailure.Message)
+	}
+	if _, exists := logs.Skips[FQTest("web/src/login.test.ts.todo_later")]; !exists {
+		t.Fatalf("Expected the todo test to be skipped. Actual: %v", logs.Skips)
+	}
+}
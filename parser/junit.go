@@ -0,0 +1,265 @@
+package parser
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JUnit XML, as written by e.g: gotestsum or jest-junit. E.g:
+//
+//	<testsuites>
+//	  <testsuite name="go.viam.com/rdk/foo" timestamp="2023-08-01T20:15:01">
+//	    <testcase classname="go.viam.com/rdk/foo" name="TestFoo" time="0.01">
+//	      <failure message="Failed" type="">foo_test.go:12: Expected: nil</failure>
+//	    </testcase>
+//	  </testsuite>
+//	</testsuites>
+type JUnitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name      string `xml:"name,attr"`
+	Timestamp string `xml:"timestamp,attr,omitempty"`
+	// The counts are only written. Parsing counts the test cases.
+	Tests    int `xml:"tests,attr,omitempty"`
+	Failures int `xml:"failures,attr,omitempty"`
+	Errors   int `xml:"errors,attr,omitempty"`
+	Skipped  int `xml:"skipped,attr,omitempty"`
+	// Some tools nest suites, e.g: one per file within one per project.
+	Suites    []JUnitTestSuite `xml:"testsuite"`
+	Cases     []JUnitTestCase  `xml:"testcase"`
+	SystemOut string           `xml:"system-out,omitempty"`
+	SystemErr string           `xml:"system-err,omitempty"`
+}
+
+type JUnitTestCase struct {
+	Name      string `xml:"name,attr"`
+	Classname string `xml:"classname,attr"`
+	// In seconds.
+	Time      string        `xml:"time,attr"`
+	Failures  []JUnitResult `xml:"failure"`
+	Errors    []JUnitResult `xml:"error"`
+	Skipped   *JUnitResult  `xml:"skipped"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+type JUnitResult struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// Replays a list of test2json records. Used for test results that were not written as test2json.
+type testLogSlice struct {
+	docs []TestLogLine
+	idx  int
+}
+
+func (docs *testLogSlice) Next() bool {
+	docs.idx++
+	return docs.idx <= len(docs.docs)
+}
+
+func (docs *testLogSlice) Doc() TestLogLine {
+	return docs.docs[docs.idx-1]
+}
+
+func (docs *testLogSlice) Err() error {
+	return nil
+}
+
+func (docs *testLogSlice) Diagnostics() Diagnostics {
+	return Diagnostics{NumLines: len(docs.docs)}
+}
+
+func (docs *testLogSlice) UntaggedOutput() []string {
+	return nil
+}
+
+// Returns the lines of an XML text element, without the leading and trailing blank lines.
+func junitLines(text string) []string {
+	text = strings.Trim(text, "\r\n")
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// Timestamps are typically local time without a zone. E.g: `2023-08-01T20:15:01`.
+func parseJUnitTimestamp(timestamp string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05"} {
+		if ret, err := time.Parse(layout, timestamp); err == nil {
+			return ret
+		}
+	}
+
+	return time.Time{}
+}
+
+// Converts the test suite into the test2json records `go test -json` would have written for it.
+// Each failure (or error) is logged as e.g: `    Failure (AssertionError): expected 1 to equal 2`,
+// followed by the failure's body.
+func (suite JUnitTestSuite) toTestLogLines() []TestLogLine {
+	ret := make([]TestLogLine, 0)
+	for _, nested := range suite.Suites {
+		ret = append(ret, nested.toTestLogLines()...)
+	}
+	if len(suite.Cases) == 0 {
+		return ret
+	}
+
+	start := parseJUnitTimestamp(suite.Timestamp)
+	elapsed := 0.0
+	at := func() string {
+		if start.IsZero() {
+			return ""
+		}
+		return start.Add(time.Duration(elapsed * float64(time.Second))).Format(time.RFC3339Nano)
+	}
+
+	pkg := suite.Name
+	packageFailed := false
+	for _, testCase := range suite.Cases {
+		if pkg == "" {
+			pkg = testCase.Classname
+		}
+		doc := func(action string, output string) TestLogLine {
+			return TestLogLine{Time: at(), Action: action, Package: pkg, Test: testCase.Name, Output: output}
+		}
+		outputLines := func(lines []string) {
+			for _, line := range lines {
+				ret = append(ret, doc("output", line+"\n"))
+			}
+		}
+
+		ret = append(ret, doc("run", ""))
+		ret = append(ret, doc("output", fmt.Sprintf("=== RUN   %v\n", testCase.Name)))
+		outputLines(junitLines(testCase.SystemOut))
+		for _, kind := range []struct {
+			name    string
+			results []JUnitResult
+		}{{"Failure", testCase.Failures}, {"Error", testCase.Errors}} {
+			for _, result := range kind.results {
+				header := "    " + kind.name
+				if result.Type != "" {
+					header = fmt.Sprintf("%v (%v)", header, result.Type)
+				}
+				ret = append(ret, doc("output", fmt.Sprintf("%v: %v\n", header, result.Message)))
+				outputLines(junitLines(result.Body))
+			}
+		}
+		outputLines(junitLines(testCase.SystemErr))
+
+		caseElapsed, _ := strconv.ParseFloat(testCase.Time, 64)
+		elapsed += caseElapsed
+		var action, result string
+		switch {
+		case len(testCase.Failures) > 0 || len(testCase.Errors) > 0:
+			action, result = "fail", "FAIL"
+			packageFailed = true
+		case testCase.Skipped != nil:
+			action, result = "skip", "SKIP"
+			if testCase.Skipped.Message != "" {
+				ret = append(ret, doc("output", fmt.Sprintf("    %v\n", testCase.Skipped.Message)))
+			}
+		default:
+			action, result = "pass", "PASS"
+		}
+		ret = append(ret, doc("output", fmt.Sprintf("--- %v: %v (%.2fs)\n", result, testCase.Name, caseElapsed)))
+		end := doc(action, "")
+		end.Elapsed = caseElapsed
+		ret = append(ret, end)
+	}
+
+	for _, line := range append(junitLines(suite.SystemOut), junitLines(suite.SystemErr)...) {
+		ret = append(ret, TestLogLine{Time: at(), Action: "output", Package: pkg, Output: line + "\n"})
+	}
+	end := TestLogLine{Time: at(), Action: "pass", Package: pkg, Elapsed: elapsed}
+	if packageFailed {
+		end.Action = "fail"
+	}
+	ret = append(ret, end)
+
+	return ret
+}
+
+// Parses JUnit XML into the same `Result` as a test2json log. The root element is either
+// `<testsuites>` or a single `<testsuite>`.
+func ParseJUnit(ctx context.Context, reader io.Reader, options Options) (*Result, error) {
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(contents, &root); err != nil {
+		return nil, fmt.Errorf("Bad JUnit XML. Err: %w", err)
+	}
+
+	var suites JUnitTestSuites
+	if root.XMLName.Local == "testsuite" {
+		var suite JUnitTestSuite
+		if err := xml.Unmarshal(contents, &suite); err != nil {
+			return nil, fmt.Errorf("Bad JUnit XML. Err: %w", err)
+		}
+		suites.Suites = []JUnitTestSuite{suite}
+	} else if err := xml.Unmarshal(contents, &suites); err != nil {
+		return nil, fmt.Errorf("Bad JUnit XML. Err: %w", err)
+	}
+
+	docs := make([]TestLogLine, 0)
+	skipMessages := make(map[FQTest]string)
+	junitErrors := make(map[FQTest][]JUnitResult)
+	for _, suite := range suites.Suites {
+		docs = append(docs, suite.toTestLogLines()...)
+		suite.visitCases(suite.Name, func(fqTest FQTest, testCase JUnitTestCase) {
+			if testCase.Skipped != nil {
+				skipMessages[fqTest] = testCase.Skipped.Message
+			}
+			if len(testCase.Errors) > 0 {
+				junitErrors[fqTest] = testCase.Errors
+			}
+		})
+	}
+
+	ret, err := parseTestLog(ctx, &testLogSlice{docs: docs}, options)
+	if err != nil {
+		return ret, err
+	}
+
+	// JUnit skip messages are not logged with a file and line. See `skipReason`.
+	for fqTest, skip := range ret.Skips {
+		if message, exists := skipMessages[fqTest]; exists {
+			skip.Reason = message
+		}
+	}
+	ret.JUnitErrors = junitErrors
+
+	return ret, nil
+}
+
+// Calls `visit` with every test case of the suite and its nested suites. Test cases are in the
+// package `pkg`, as in `toTestLogLines`.
+func (suite JUnitTestSuite) visitCases(pkg string, visit func(fqTest FQTest, testCase JUnitTestCase)) {
+	for _, nested := range suite.Suites {
+		nested.visitCases(nested.Name, visit)
+	}
+
+	for _, testCase := range suite.Cases {
+		if pkg == "" {
+			pkg = testCase.Classname
+		}
+		visit(TestLogLine{Package: pkg, Test: testCase.Name}.ToFQTest(), testCase)
+	}
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseJUnit(t *testing.T) {
+	const junitXML = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="5" failures="1" errors="1" skipped="1">
+  <testsuite name="go.viam.com/rdk/components/arm" tests="3" timestamp="2023-08-01T20:15:01">
+    <testcase classname="go.viam.com/rdk/components/arm" name="TestArmPosition" time="0.50">
+      <failure message="Failed" type="">
+    arm_test.go:42: Expected: 1
+        Actual: 2
+      </failure>
+    </testcase>
+    <testcase classname="go.viam.com/rdk/components/arm" name="TestArmMove" time="1.25">
+      <system-out>moving the arm</system-out>
+      <error message="connection refused" type="NetError">dial tcp 127.0.0.1:8080</error>
+    </testcase>
+    <testcase classname="go.viam.com/rdk/components/arm" name="TestArmSim" time="0">
+      <skipped message="flaky, see RSDK-1234"></skipped>
+    </testcase>
+  </testsuite>
+  <testsuite name="go.viam.com/rdk/components/base" tests="1">
+    <testcase classname="go.viam.com/rdk/components/base" name="TestBase" time="0.01"></testcase>
+  </testsuite>
+</testsuites>`
+
+	logs, err := ParseJUnit(context.Background(), strings.NewReader(junitXML), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logs.TestFailures) != 2 {
+		t.Fatalf("Expected two failures. Actual: %v", logs.TestFailures)
+	}
+
+	const armPkg = "go.viam.com/rdk/components/arm"
+	position := FQTest(armPkg + ".TestArmPosition")
+	if assertions := logs.Assertions[position]; len(assertions) != 1 || assertions[0].Expected != "1" ||
+		strings.TrimSpace(assertions[0].Actual) != "2" {
+		t.Fatalf("Expected the failure body to be parsed as an assertion. Actual: %+v", assertions)
+	}
+
+	move := FQTest(armPkg + ".TestArmMove")
+	if _, exists := logs.UnknownFailures[move]; !exists {
+		t.Fatalf("Expected the error to be an unknown failure. Actual: %v", logs.TestFailures)
+	}
+	if errors := logs.JUnitErrors[move]; len(errors) != 1 || errors[0].Type != "NetError" ||
+		len(logs.JUnitErrors[position]) != 0 {
+		t.Fatalf("Expected only the error to be kept as one. Actual: %+v", logs.JUnitErrors)
+	}
+	moveLogs := strings.Join(logs.Logs[move], "\n")
+	for _, expected := range []string{"moving the arm", "Error (NetError): connection refused", "dial tcp 127.0.0.1:8080"} {
+		if !strings.Contains(moveLogs, expected) {
+			t.Fatalf("Expected the logs to contain `%v`. Actual:\n%v", expected, moveLogs)
+		}
+	}
+
+	skip := logs.Skips[FQTest(armPkg+".TestArmSim")]
+	if skip == nil || skip.Reason != "flaky, see RSDK-1234" {
+		t.Fatalf("Wrong skip: %+v", skip)
+	}
+
+	if record := logs.Packages[armPkg]; record == nil || record.Status != "fail" {
+		t.Fatalf("Expected the suite to fail. Actual: %+v", record)
+	}
+	if record := logs.Packages["go.viam.com/rdk/components/base"]; record == nil || record.Status != "pass" {
+		t.Fatalf("Expected the suite to pass. Actual: %+v", record)
+	}
+}
+
+func TestParseJUnitSingleSuite(t *testing.T) {
+	const junitXML = `<testsuite name="app/web">
+  <testcase classname="app/web" name="renders the login page" time="0.1">
+    <failure message="expected true to be false" type="AssertionError"/>
+  </testcase>
+</testsuite>`
+
+	logs, err := ParseJUnit(context.Background(), strings.NewReader(junitXML), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fqTest := FQTest("app/web.renders the login page")
+	if len(logs.TestFailures) != 1 || logs.TestFailures[0] != fqTest {
+		t.Fatalf("Wrong failures: %v", logs.TestFailures)
+	}
+	if !strings.Contains(strings.Join(logs.Logs[fqTest], "\n"), "Failure (AssertionError): expected true to be false") {
+		t.Fatalf("Expected the failure message in the logs. Actual: %v", logs.Logs[fqTest])
+	}
+}
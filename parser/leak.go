@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// Goroutines left running after a test (or all of a package's tests) completed, as reported by
+// `goleak`. E.g: from `goleak.VerifyNone(t)` or `testutils.VerifyTestMain`:
+//
+//	goleak: Errors on successful test run: found unexpected goroutines:
+//	[Goroutine 32 in state select, with go.viam.com/rdk/robot/impl.(*localRobot).run on top of the stack:
+//	goroutine 32 [select]:
+//	go.viam.com/rdk/robot/impl.(*localRobot).run(0xc000282ea0)
+//		/__w/rdk/rdk/robot/impl/local_robot.go:123 +0x789
+//	created by go.viam.com/rdk/robot/impl.New in goroutine 7
+//		/__w/rdk/rdk/robot/impl/local_robot.go:42 +0x75e
+//	]
+type LeakFailure struct {
+	Package string
+	// Empty for leaks found after all of the package's tests completed.
+	Test       string
+	Goroutines []*Goroutine
+	LogLines   []string
+}
+
+// The message goleak prefixes its report with.
+const leakStartMarker = "found unexpected goroutines:"
+
+func newLeakFailure(doc TestLogLine) *LeakFailure {
+	return &LeakFailure{
+		Package:  doc.Package,
+		Test:     doc.Test,
+		LogLines: []string{doc.Output},
+	}
+}
+
+// Returns true for the line that follows a goleak report. E.g: the `--- FAIL` of the leaking test
+// or the `FAIL` of the package.
+func isLeakReportEnd(line string) bool {
+	for _, prefix := range []string{"--- ", "=== ", "FAIL", "PASS", "ok ", "exit status", "coverage:", "panic:"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+
+	return strings.Contains(line, leakStartMarker)
+}
+
+// Fills in the `Goroutines` from the leak's log lines.
+func (leak *LeakFailure) parseLogLines(ownModulePrefixes []string) {
+	// A report from `t.Error` is indented.
+	lines := make([]string, 0, len(leak.LogLines))
+	for _, line := range leak.LogLines {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	leak.Goroutines = parseGoroutineDump(lines, ownModulePrefixes)
+}
+
+func (leak *LeakFailure) ToFQTest() FQTest {
+	return TestLogLine{Package: leak.Package, Test: leak.Test}.ToFQTest()
+}
+
+// Returns the frame that best identifies a leaked goroutine: the top frame in our own code, else
+// where the goroutine was created, else its top frame. Leaked goroutines are most often parked in
+// the runtime or standard library.
+func leakedFrame(goroutine *Goroutine) *StackFrame {
+	for idx := range goroutine.Frames {
+		if goroutine.Frames[idx].OwnCode {
+			return &goroutine.Frames[idx]
+		}
+	}
+
+	if goroutine.CreatedBy != nil {
+		return goroutine.CreatedBy
+	}
+
+	if len(goroutine.Frames) > 0 {
+		return &goroutine.Frames[0]
+	}
+
+	return nil
+}
+
+// Prefixes a leaked goroutine's signature in ticket descriptions.
+const leakSignaturePrefix = "Goroutine leak signature: "
+
+// Returns one signature line per distinct leaked function. E.g:
+// `Goroutine leak signature: go.viam.com/rdk/robot/impl.(*localRobot).run`.
+func (leak *LeakFailure) Signatures() []string {
+	funcs := make(map[string]struct{})
+	for _, goroutine := range leak.Goroutines {
+		if frame := leakedFrame(goroutine); frame != nil && frame.Func != "" {
+			funcs[frame.Func] = struct{}{}
+		}
+	}
+
+	ret := make([]string, 0, len(funcs))
+	for funcName := range funcs {
+		ret = append(ret, leakSignaturePrefix+funcName)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+// E.g:
+//
+//	found unexpected goroutines:
+//	1 goroutines [select]:
+//	  => go.viam.com/rdk/robot/impl.(*localRobot).run local_robot.go:123
+//	Goroutine leak signature: go.viam.com/rdk/robot/impl.(*localRobot).run
+func (leak *LeakFailure) ToPrettyString(indent string) string {
+	lines := []string{indent + leakStartMarker}
+	if len(leak.Goroutines) > 0 {
+		lines = append(lines, summarizeGoroutines(leak.Goroutines, indent))
+	}
+	for _, signature := range leak.Signatures() {
+		lines = append(lines, indent+signature)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestGoroutineLeak(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	output := func(test, line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+
+	logs, err := Parse(context.Background(), parsertest.LogReader(
+		// `goleak.VerifyNone(t)` reports through `t.Error`.
+		output("TestRobotClose", "=== RUN   TestRobotClose"),
+		output("TestRobotClose", "    local_robot_test.go:42: found unexpected goroutines:"),
+		output("TestRobotClose", "        [Goroutine 32 in state select, with go.viam.com/rdk/robot/impl.(*localRobot).run on top of the stack:"),
+		output("TestRobotClose", "        goroutine 32 [select]:"),
+		output("TestRobotClose", "        go.viam.com/rdk/robot/impl.(*localRobot).run(0xc000282ea0)"),
+		output("TestRobotClose", "        \t/__w/rdk/rdk/robot/impl/local_robot.go:123 +0x789"),
+		output("TestRobotClose", "        created by go.viam.com/rdk/robot/impl.New in goroutine 7"),
+		output("TestRobotClose", "        \t/__w/rdk/rdk/robot/impl/local_robot.go:42 +0x75e"),
+		output("TestRobotClose", "        ]"),
+		output("TestRobotClose", "--- FAIL: TestRobotClose (0.01s)"),
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestRobotClose"},
+		// `goleak.VerifyTestMain` reports after all tests passed.
+		output("", "PASS"),
+		output("", "goleak: Errors on successful test run: found unexpected goroutines:"),
+		output("", "[Goroutine 50 in state IO wait, with internal/poll.runtime_pollWait on top of the stack:"),
+		output("", "goroutine 50 [IO wait]:"),
+		output("", "internal/poll.runtime_pollWait(0x7f, 0x72)"),
+		output("", "\t/usr/local/go/src/runtime/netpoll.go:343 +0x85"),
+		output("", "created by go.viam.com/rdk/robot/web.(*webService).Start in goroutine 7"),
+		output("", "\t/__w/rdk/rdk/robot/web/web.go:99 +0x75e"),
+		output("", "]"),
+		output("", "FAIL\tgo.viam.com/rdk/robot/impl\t1.234s"),
+		TestLogLine{Action: "fail", Package: pkg},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testLeak := logs.Leaks[FQTest(pkg+".TestRobotClose")]
+	if testLeak == nil || len(testLeak.Goroutines) != 1 {
+		t.Fatalf("Expected a leak for the test. Leaks: %v", logs.Leaks)
+	}
+	if signatures := testLeak.Signatures(); len(signatures) != 1 ||
+		signatures[0] != "Goroutine leak signature: go.viam.com/rdk/robot/impl.(*localRobot).run" {
+		t.Fatalf("Wrong signatures: %v", signatures)
+	}
+
+	packageLeak := logs.Leaks[FQTest(pkg)]
+	if packageLeak == nil || len(packageLeak.Goroutines) != 1 {
+		t.Fatalf("Expected a leak for the package. Leaks: %v", logs.Leaks)
+	}
+	// The leaked goroutine is parked in the standard library. It is identified by where it was
+	// created.
+	if signatures := packageLeak.Signatures(); len(signatures) != 1 ||
+		!strings.HasSuffix(signatures[0], "go.viam.com/rdk/robot/web.(*webService).Start") {
+		t.Fatalf("Wrong signatures: %v", signatures)
+	}
+
+	if len(logs.TestFailures) != 2 || len(logs.UnknownFailures) != 0 {
+		t.Fatalf("Expected only the leaks to fail. Failures: %v Unknown: %v",
+			logs.TestFailures, logs.UnknownFailures)
+	}
+}
@@ -0,0 +1,237 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The lifecycle of a single test (or subtest) built from its test2json actions.
+type TestRecord struct {
+	Package string
+	// E.g: `TestMoveOnGlobe/go_around_an_obstacle`.
+	Name string
+	// The parent of a subtest. Empty for top-level tests.
+	Parent   FQTest
+	Subtests []FQTest
+	// From the `run` action.
+	Start time.Time
+	// From the final `pass`, `fail`, `skip` or `bench` action. Zero if the test never finished.
+	End time.Time
+	// The test's own elapsed time in seconds, as reported by the final action.
+	Elapsed float64
+	// The number of `run` actions. More than one if the test was rerun, e.g: by
+	// `gotestsum --rerun-fails`.
+	Runs int
+	// Periods a parallel test spent paused, waiting for its turn to run.
+	Pauses []Pause
+	// The last action seen for the test. One of `run`, `pause`, `cont`, `pass`, `fail`, `skip` or
+	// `bench`. A test that did not finish is left in `run`, `pause` or `cont`.
+	Status string
+	// Test attributes from `attr` actions. E.g: set with `t.Attr` (go 1.25+).
+	Attrs map[string]string
+}
+
+type Pause struct {
+	Start time.Time
+	// Zero if the test was never continued.
+	End time.Time
+}
+
+// The lifecycle of a package's test binary.
+type PackageRecord struct {
+	Package string
+	// From the `start` action (go 1.20+).
+	Start   time.Time
+	End     time.Time
+	Elapsed float64
+	// One of `start`, `pass`, `fail` or `skip`. A package that did not finish is left in `start`.
+	Status string
+}
+
+// Returns the time the action happened. Returns the zero time for actions without a (valid)
+// timestamp.
+func (testLogLine TestLogLine) ParsedTime() time.Time {
+	ret, err := time.Parse(time.RFC3339Nano, testLogLine.Time)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return ret
+}
+
+func (record *TestRecord) ToFQTest() FQTest {
+	return TestLogLine{Package: record.Package, Test: record.Name}.ToFQTest()
+}
+
+// Returns true if the test ran to completion.
+func (record *TestRecord) IsFinished() bool {
+	switch record.Status {
+	case "pass", "fail", "skip", "bench":
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns the record for the test, creating it (and linking it to its parent) if needed.
+func (result *Result) getTestRecord(doc TestLogLine) *TestRecord {
+	fqTest := doc.ToFQTest()
+	if record, exists := result.Tests[fqTest]; exists {
+		return record
+	}
+
+	record := &TestRecord{
+		Package: doc.Package,
+		Name:    doc.Test,
+		Attrs:   make(map[string]string),
+	}
+	// Subtest names are the parent's name followed by a `/`. E.g: `TestMoveOnGlobe/go_around`.
+	if idx := strings.LastIndex(doc.Test, "/"); idx >= 0 {
+		parent := result.getTestRecord(TestLogLine{Package: doc.Package, Test: doc.Test[:idx]})
+		record.Parent = parent.ToFQTest()
+		parent.Subtests = append(parent.Subtests, fqTest)
+	}
+	result.Tests[fqTest] = record
+
+	return record
+}
+
+// Updates the test and package lifecycles with a test2json action.
+func (result *Result) recordLifecycle(doc TestLogLine) {
+	if doc.Package == "" {
+		return
+	}
+
+	if doc.Test == "" {
+		record, exists := result.Packages[doc.Package]
+		if !exists {
+			record = &PackageRecord{Package: doc.Package}
+			result.Packages[doc.Package] = record
+		}
+
+		switch doc.Action {
+		case "start":
+			record.Start = doc.ParsedTime()
+			record.Status = doc.Action
+		case "pass", "fail", "skip":
+			record.End = doc.ParsedTime()
+			record.Elapsed = doc.Elapsed
+			record.Status = doc.Action
+		}
+		return
+	}
+
+	switch doc.Action {
+	case "run":
+		record := result.getTestRecord(doc)
+		record.Start = doc.ParsedTime()
+		record.Runs++
+		record.Status = doc.Action
+	case "pause":
+		record := result.getTestRecord(doc)
+		record.Pauses = append(record.Pauses, Pause{Start: doc.ParsedTime()})
+		record.Status = doc.Action
+	case "cont":
+		record := result.getTestRecord(doc)
+		if numPauses := len(record.Pauses); numPauses > 0 {
+			record.Pauses[numPauses-1].End = doc.ParsedTime()
+		}
+		record.Status = doc.Action
+	case "pass", "fail", "skip":
+		record := result.getTestRecord(doc)
+		record.End = doc.ParsedTime()
+		record.Elapsed = doc.Elapsed
+		record.Status = doc.Action
+	case "bench":
+		// Benchmarks report their results with `bench` actions. Don't let a benchmark's results
+		// override a final `fail`.
+		record := result.getTestRecord(doc)
+		if !record.IsFinished() {
+			record.End = doc.ParsedTime()
+			record.Status = doc.Action
+		}
+	case "attr":
+		record := result.getTestRecord(doc)
+		record.Attrs[doc.Key] = doc.Value
+	}
+}
+
+// Returns true if any subtest (at any depth) of the test failed.
+func (result *Result) hasFailedSubtest(fqTest FQTest, failed map[FQTest]struct{}) bool {
+	record, exists := result.Tests[fqTest]
+	if !exists {
+		return false
+	}
+
+	for _, subtest := range record.Subtests {
+		if _, isFailed := failed[subtest]; isFailed {
+			return true
+		}
+		if subRecord := result.Tests[subtest]; subRecord != nil && subRecord.Status == "fail" {
+			return true
+		}
+		if result.hasFailedSubtest(subtest, failed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the parent tests of a subtest, outermost first. E.g: `TestMoveOnGlobe/obstacle/foo`
+// returns `TestMoveOnGlobe` and `TestMoveOnGlobe/obstacle`. Returns nothing for top-level tests.
+func (result *Result) Ancestry(fqTest FQTest) []FQTest {
+	ret := make([]FQTest, 0)
+	for record := result.Tests[fqTest]; record != nil && record.Parent != ""; record = result.Tests[record.Parent] {
+		ret = append([]FQTest{record.Parent}, ret...)
+	}
+
+	return ret
+}
+
+// Returns the test names of the subtest's parents. E.g: `TestMoveOnGlobe > TestMoveOnGlobe/obstacle`.
+func (result *Result) AncestryString(fqTest FQTest) string {
+	names := make([]string, 0)
+	for _, ancestor := range result.Ancestry(fqTest) {
+		names = append(names, result.Tests[ancestor].Name)
+	}
+
+	return strings.Join(names, " > ")
+}
+
+// E.g: "    arm_test.go:42: unexpected error". Output of the test itself, as printed by `t.Error` and
+// `t.Fatal`, rather than by a logger.
+var testErrorRe *regexp.Regexp = regexp.MustCompile(
+	`^\s+\S+_test\.go:\d+: `)
+
+// Returns true if the test printed output of its own that may explain its failure.
+func hasOwnErrorOutput(logs []string) bool {
+	for _, line := range logs {
+		if testErrorRe.MatchString(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A failing subtest also fails its parent. Remove parents from the `TestFailures` that failed only
+// because a subtest failed. Parents with a failure of their own (e.g: an assertion or a `t.Error`
+// in `testLogs`) are kept.
+func (result *Result) suppressParentFailures(testLogs map[FQTest][]string) {
+	failed := make(map[FQTest]struct{})
+	for _, testFailure := range result.TestFailures {
+		failed[testFailure] = struct{}{}
+	}
+
+	kept := make([]FQTest, 0, len(result.TestFailures))
+	for _, testFailure := range result.TestFailures {
+		if !result.IsClassified(testFailure) && !hasOwnErrorOutput(testLogs[testFailure]) &&
+			result.hasFailedSubtest(testFailure, failed) {
+			continue
+		}
+		kept = append(kept, testFailure)
+	}
+	result.TestFailures = kept
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestLifecycle(t *testing.T) {
+	const pkg = "go.viam.com/rdk/motionplan"
+	at := func(seconds int) string {
+		return time.Date(2023, 9, 8, 22, 29, seconds, 0, time.UTC).Format(time.RFC3339Nano)
+	}
+	action := func(seconds int, action, test string) TestLogLine {
+		return TestLogLine{Time: at(seconds), Action: action, Package: pkg, Test: test}
+	}
+
+	output, err := Parse(context.Background(), parsertest.LogReader(
+		action(0, "start", ""),
+		action(1, "run", "TestUnconstrainedMotion"),
+		action(1, "run", "TestUnconstrainedMotion/2D_plan_test"),
+		action(1, "pause", "TestUnconstrainedMotion/2D_plan_test"),
+		TestLogLine{Time: at(2), Action: "attr", Package: pkg, Test: "TestUnconstrainedMotion", Key: "owner", Value: "motion"},
+		action(3, "cont", "TestUnconstrainedMotion/2D_plan_test"),
+		TestLogLine{Time: at(5), Action: "pass", Package: pkg, Test: "TestUnconstrainedMotion/2D_plan_test", Elapsed: 2},
+		action(5, "run", "TestUnconstrainedMotion/6D_plan_test"),
+		action(6, "run", "TestSkipped"),
+		action(6, "skip", "TestSkipped"),
+		TestLogLine{Time: at(9), Action: "fail", Package: pkg, Elapsed: 9},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if record := output.Packages[pkg]; record == nil || record.Status != "fail" ||
+		record.Start != time.Date(2023, 9, 8, 22, 29, 0, 0, time.UTC) || record.Elapsed != 9 {
+		t.Fatalf("Wrong package record: %+v", record)
+	}
+
+	parent := output.Tests[FQTest(pkg+".TestUnconstrainedMotion")]
+	if parent == nil || len(parent.Subtests) != 2 || parent.Attrs["owner"] != "motion" || parent.IsFinished() {
+		t.Fatalf("Wrong parent record: %+v", parent)
+	}
+
+	subtest := output.Tests[FQTest(pkg+".TestUnconstrainedMotion/2D_plan_test")]
+	if subtest == nil || subtest.Parent != parent.ToFQTest() || subtest.Status != "pass" || subtest.Elapsed != 2 {
+		t.Fatalf("Wrong subtest record: %+v", subtest)
+	}
+	if len(subtest.Pauses) != 1 || subtest.Pauses[0].End.Sub(subtest.Pauses[0].Start) != 2*time.Second {
+		t.Fatalf("Wrong pauses: %+v", subtest.Pauses)
+	}
+
+	if unfinished := output.Tests[FQTest(pkg+".TestUnconstrainedMotion/6D_plan_test")]; unfinished.Status != "run" {
+		t.Fatalf("Wrong unfinished record: %+v", unfinished)
+	}
+	if skipped := output.Tests[FQTest(pkg+".TestSkipped")]; skipped.Status != "skip" {
+		t.Fatalf("Wrong skipped record: %+v", skipped)
+	}
+}
+
+func TestSubtestHierarchy(t *testing.T) {
+	const pkg = "go.viam.com/rdk/services/motion/builtin"
+	output, err := Parse(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnGlobe"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnGlobe/go_around_an_obstacle"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnGlobe/go_around_an_obstacle/nested"},
+		TestLogLine{Action: "output", Package: pkg, Test: "TestMoveOnGlobe/go_around_an_obstacle/nested", Output: "    motion_test.go:42: something went wrong\n"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestMoveOnGlobe/go_around_an_obstacle/nested"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestMoveOnGlobe/go_around_an_obstacle"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestMoveOnGlobe"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnMap"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnMap/sub"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestMoveOnMap/sub"},
+		TestLogLine{Action: "output", Package: pkg, Test: "TestMoveOnMap", Output: "    motion_test.go:99: Expected: 1\n"},
+		TestLogLine{Action: "output", Package: pkg, Test: "TestMoveOnMap", Output: "        Actual:   2\n"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestMoveOnMap"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnPath"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnPath/sub"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestMoveOnPath/sub"},
+		TestLogLine{Action: "output", Package: pkg, Test: "TestMoveOnPath", Output: "    motion_test.go:9: parent own error\n"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestMoveOnPath"},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf := FQTest(pkg + ".TestMoveOnGlobe/go_around_an_obstacle/nested")
+	parentWithAssertion := FQTest(pkg + ".TestMoveOnMap")
+	parentWithError := FQTest(pkg + ".TestMoveOnPath")
+	if len(output.TestFailures) != 5 {
+		t.Fatalf("Expected the leaf subtests and the parents with failures of their own. Actual: %v", output.TestFailures)
+	}
+	for _, expected := range []FQTest{
+		leaf, parentWithAssertion, FQTest(pkg + ".TestMoveOnMap/sub"), parentWithError, FQTest(pkg + ".TestMoveOnPath/sub"),
+	} {
+		found := false
+		for _, testFailure := range output.TestFailures {
+			found = found || testFailure == expected
+		}
+		if !found {
+			t.Fatalf("Missing failure: %v Actual: %v", expected, output.TestFailures)
+		}
+	}
+
+	if ancestry := output.AncestryString(leaf); ancestry != "TestMoveOnGlobe > TestMoveOnGlobe/go_around_an_obstacle" {
+		t.Fatalf("Wrong ancestry: %v", ancestry)
+	}
+	if ancestry := output.AncestryString(parentWithAssertion); ancestry != "" {
+		t.Fatalf("Expected no ancestry for a top-level test. Actual: %v", ancestry)
+	}
+}
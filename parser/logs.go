@@ -0,0 +1,240 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A zap log line parsed out of test result. E.g:
+//
+//	logger.go:130: 2023-08-01T20:15:01.677Z	WARN	robot	agilex/limo_base.go:137	base not configured	{"name": "limo"}
+type LogEntry struct {
+	Time time.Time
+	// E.g: `DEBUG`, `INFO`, `WARN` or `ERROR`.
+	Level string
+	// The logger's name. Empty for the root logger.
+	Logger string
+	// E.g: `agilex/limo_base.go:137`.
+	Caller  string
+	Message string
+	// The structured fields, as printed. E.g: `{"name": "limo"}`.
+	Fields string
+	Raw    string
+}
+
+// The zap levels, in order of severity.
+var logLevels = []string{"DEBUG", "INFO", "WARN", "ERROR", "DPANIC", "PANIC", "FATAL"}
+
+// E.g: "    logger.go:130: 2023-08-01T20:15:01.677Z\tWARN\tagilex/limo_base.go:137\tbase not configured"
+var consoleLogRe *regexp.Regexp = regexp.MustCompile(
+	`^\s*(?:\S+\.go:\d+: )?(\d{4}-\d{2}-\d{2}T\S+)\t(\S+)\t(.*)$`)
+
+// E.g: "    logger.go:130: {"level":"warn","ts":1690920901.677,"msg":"base not configured"}"
+var jsonLogRe *regexp.Regexp = regexp.MustCompile(
+	`^\s*(?:\S+\.go:\d+: )?(\{.*\})$`)
+
+// E.g: "agilex/limo_base.go:137"
+var logCallerRe *regexp.Regexp = regexp.MustCompile(
+	`^\S+\.go:\d+$`)
+
+// Color codes, e.g: around the level of a development logger.
+var ansiColorRe *regexp.Regexp = regexp.MustCompile(
+	"\x1b\\[[0-9;]*m")
+
+func levelSeverity(level string) int {
+	for idx, known := range logLevels {
+		if level == known {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// Returns true for `WARN` and more severe levels.
+func (entry *LogEntry) IsWarnOrAbove() bool {
+	return levelSeverity(entry.Level) >= levelSeverity("WARN")
+}
+
+// Parses a zap console or JSON encoded log line. Returns false for other lines.
+func parseLogEntry(line string) (*LogEntry, bool) {
+	line = ansiColorRe.ReplaceAllString(line, "")
+	if matches := consoleLogRe.FindStringSubmatch(line); len(matches) > 0 {
+		ts, err := time.Parse(time.RFC3339Nano, matches[1])
+		if err != nil || levelSeverity(matches[2]) < 0 {
+			return nil, false
+		}
+
+		entry := &LogEntry{Time: ts, Level: matches[2], Raw: line}
+		parts := strings.Split(matches[3], "\t")
+		// The logger name is only printed for named loggers.
+		if len(parts) > 1 && !logCallerRe.MatchString(parts[0]) && logCallerRe.MatchString(parts[1]) {
+			entry.Logger, parts = parts[0], parts[1:]
+		}
+		if len(parts) > 1 && logCallerRe.MatchString(parts[0]) {
+			entry.Caller, parts = parts[0], parts[1:]
+		}
+		entry.Message = parts[0]
+		entry.Fields = strings.Join(parts[1:], "\t")
+
+		return entry, true
+	}
+
+	if matches := jsonLogRe.FindStringSubmatch(line); len(matches) > 0 {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(matches[1]), &fields); err != nil {
+			return nil, false
+		}
+
+		level, _ := fields["level"].(string)
+		msg, hasMsg := fields["msg"].(string)
+		if levelSeverity(strings.ToUpper(level)) < 0 || !hasMsg {
+			return nil, false
+		}
+
+		entry := &LogEntry{Level: strings.ToUpper(level), Message: msg, Raw: line}
+		entry.Logger, _ = fields["logger"].(string)
+		entry.Caller, _ = fields["caller"].(string)
+		switch ts := fields["ts"].(type) {
+		case float64:
+			// Seconds since the epoch.
+			secs, frac := math.Modf(ts)
+			entry.Time = time.Unix(int64(secs), int64(frac*1e9)).UTC()
+		case string:
+			entry.Time, _ = time.Parse(time.RFC3339Nano, ts)
+		}
+		for _, key := range []string{"level", "ts", "logger", "caller", "msg"} {
+			delete(fields, key)
+		}
+		if len(fields) > 0 {
+			if encoded, err := json.Marshal(fields); err == nil {
+				entry.Fields = string(encoded)
+			}
+		}
+
+		return entry, true
+	}
+
+	return nil, false
+}
+
+// Returns the zap log entries in the lines. Other lines are ignored.
+func parseLogEntries(lines []string) []*LogEntry {
+	ret := make([]*LogEntry, 0)
+	for _, line := range lines {
+		if entry, ok := parseLogEntry(line); ok {
+			ret = append(ret, entry)
+		}
+	}
+
+	return ret
+}
+
+// E.g: "2023-08-01T20:15:01.677Z WARN robot agilex/limo_base.go:137 base not configured"
+func (entry *LogEntry) ToPrettyString() string {
+	parts := []string{entry.Time.Format(time.RFC3339Nano), entry.Level}
+	for _, part := range []string{entry.Logger, entry.Caller, entry.Message, entry.Fields} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Returns the `WARN` and more severe entries, grouped by logger. The root logger's name is empty.
+func groupWarningsByLogger(entries []*LogEntry) map[string][]*LogEntry {
+	ret := make(map[string][]*LogEntry)
+	for _, entry := range entries {
+		if entry.IsWarnOrAbove() {
+			ret[entry.Logger] = append(ret[entry.Logger], entry)
+		}
+	}
+
+	return ret
+}
+
+// How far back from a failure log entries are considered relevant.
+const logWindowBeforeFailure = 5 * time.Second
+
+// Returns the entries logged within `window` before (and including) `failureTime`.
+func entriesBefore(entries []*LogEntry, failureTime time.Time, window time.Duration) []*LogEntry {
+	ret := make([]*LogEntry, 0)
+	for _, entry := range entries {
+		if !entry.Time.After(failureTime) && failureTime.Sub(entry.Time) <= window {
+			ret = append(ret, entry)
+		}
+	}
+
+	return ret
+}
+
+// Returns when the test failed: the time of its first assertion, else when the test finished,
+// else when it logged last.
+func (result *Result) FailureTime(fqTest FQTest) time.Time {
+	for _, assertion := range result.Assertions[fqTest] {
+		if !assertion.Time.IsZero() {
+			return assertion.Time
+		}
+	}
+
+	if record := result.Tests[fqTest]; record != nil && !record.End.IsZero() {
+		return record.End
+	}
+
+	if entries := result.LogEntries[fqTest]; len(entries) > 0 {
+		return entries[len(entries)-1].Time
+	}
+
+	return time.Time{}
+}
+
+// Returns the test's structured logs: the entries from right before the failure, followed by the
+// warnings and errors grouped by logger. Returns nothing if the test did not log with zap. E.g:
+//
+//	Logs in the 5s before the failure:
+//	  2023-08-01T20:15:01.677Z INFO robot.impl local_robot.go:123 reconfiguring
+//	Warnings and errors by logger:
+//	  robot.impl:
+//	    2023-08-01T20:15:01.681Z WARN robot.impl local_robot.go:456 failed to connect
+func (result *Result) LogSummary(fqTest FQTest, indent string) []string {
+	entries := result.LogEntries[fqTest]
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ret := make([]string, 0)
+	if before := entriesBefore(entries, result.FailureTime(fqTest), logWindowBeforeFailure); len(before) > 0 {
+		ret = append(ret, fmt.Sprintf("%sLogs in the %v before the failure:", indent, logWindowBeforeFailure))
+		for _, entry := range before {
+			ret = append(ret, fmt.Sprintf("%s  %v", indent, entry.ToPrettyString()))
+		}
+	}
+
+	byLogger := groupWarningsByLogger(entries)
+	loggers := make([]string, 0, len(byLogger))
+	for logger := range byLogger {
+		loggers = append(loggers, logger)
+	}
+	sort.Strings(loggers)
+	if len(loggers) > 0 {
+		ret = append(ret, indent+"Warnings and errors by logger:")
+	}
+	for _, logger := range loggers {
+		name := logger
+		if name == "" {
+			name = "<root>"
+		}
+		ret = append(ret, fmt.Sprintf("%s  %v:", indent, name))
+		for _, entry := range byLogger[logger] {
+			ret = append(ret, fmt.Sprintf("%s    %v", indent, entry.ToPrettyString()))
+		}
+	}
+
+	return ret
+}
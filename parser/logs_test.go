@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestParseLogEntry(t *testing.T) {
+	entry, ok := parseLogEntry("    logger.go:130: 2023-08-01T20:15:01.677Z\tWARN\tagilex/limo_base.go:137\tbase not configured")
+	if !ok || entry.Level != "WARN" || entry.Logger != "" || entry.Caller != "agilex/limo_base.go:137" ||
+		entry.Message != "base not configured" {
+		t.Fatalf("Wrong entry: %+v", entry)
+	}
+
+	entry, ok = parseLogEntry("    logger.go:130: 2023-08-01T20:15:01.677Z\t\x1b[31mERROR\x1b[0m\trobot.impl\timpl/local_robot.go:42\tfailed\t{\"err\": \"boom\"}")
+	if !ok || entry.Level != "ERROR" || entry.Logger != "robot.impl" || entry.Message != "failed" ||
+		entry.Fields != `{"err": "boom"}` {
+		t.Fatalf("Wrong entry: %+v", entry)
+	}
+
+	entry, ok = parseLogEntry(`    logger.go:130: {"level":"info","ts":1690920901.5,"logger":"robot","caller":"impl/local_robot.go:42","msg":"started","name":"limo"}`)
+	if !ok || entry.Level != "INFO" || entry.Logger != "robot" || entry.Fields != `{"name":"limo"}` ||
+		!entry.Time.Equal(time.Unix(1690920901, 5e8)) {
+		t.Fatalf("Wrong entry: %+v", entry)
+	}
+
+	if _, ok := parseLogEntry("    ur5e_test.go:384: Expected: nil"); ok {
+		t.Fatal("Expected a test message not to parse as a log entry")
+	}
+}
+
+func TestLogSummary(t *testing.T) {
+	const pkg = "go.viam.com/rdk/components/arm/universalrobots"
+	output := func(at, line string) TestLogLine {
+		return TestLogLine{
+			Time: "2023-08-01T20:16:" + at + "Z", Action: "output", Package: pkg, Test: "TestArmReconnection",
+			Output: line + "\n",
+		}
+	}
+
+	logs, err := Parse(context.Background(), parsertest.LogReader(
+		output("00.0", "    logger.go:130: 2023-08-01T20:16:00.000Z\tWARN\tur/ur5e.go:12\ttoo early"),
+		output("05.0", "    logger.go:130: 2023-08-01T20:16:05.000Z\tINFO\tarm\tur/ur5e.go:34\treconnecting"),
+		output("08.0", "    logger.go:130: 2023-08-01T20:16:08.000Z\tERROR\tarm\tur/ur5e.go:56\tcannot connect"),
+		output("09.0", "    ur5e_test.go:384: Expected: nil"),
+		output("09.0", "        Actual:   'timeout'"),
+		output("09.5", "    logger.go:130: 2023-08-01T20:16:09.500Z\tINFO\tarm\tur/ur5e.go:78\tclosing"),
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestArmReconnection"},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fqTest := FQTest(pkg + ".TestArmReconnection")
+	if entries := logs.LogEntries[fqTest]; len(entries) != 4 {
+		t.Fatalf("Expected four log entries. Actual: %v", entries)
+	}
+
+	summary := strings.Join(logs.LogSummary(fqTest, ""), "\n")
+	expected := strings.Join([]string{
+		"Logs in the 5s before the failure:",
+		"  2023-08-01T20:16:05Z INFO arm ur/ur5e.go:34 reconnecting",
+		"  2023-08-01T20:16:08Z ERROR arm ur/ur5e.go:56 cannot connect",
+		"Warnings and errors by logger:",
+		"  <root>:",
+		"    2023-08-01T20:16:00Z WARN ur/ur5e.go:12 too early",
+		"  arm:",
+		"    2023-08-01T20:16:08Z ERROR arm ur/ur5e.go:56 cannot connect",
+	}, "\n")
+	if summary != expected {
+		t.Fatalf("Wrong log summary. Expected:\n%v\nActual:\n%v", expected, summary)
+	}
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A set of failure detectors. Test failures that no enabled detector matches are still reported,
+// as `UnknownFailures`.
+type DetectorSet uint
+
+const (
+	// E.g: "    arm_test.go:42: Expected: 1" followed by "        Actual:   2".
+	DetectAssertions DetectorSet = 1 << iota
+	// E.g: "panic: test timed out after 10m0s".
+	DetectTimeouts
+	// E.g: "WARNING: DATA RACE".
+	DetectDataraces
+	// Panics, runtime errors and go fatal errors.
+	DetectCrashes
+	// E.g: "goleak: Errors on successful test run: found unexpected goroutines:".
+	DetectLeaks
+	// Fuzz tests that found a failing input.
+	DetectFuzz
+	// Test binaries that were killed or ran out of resources. E.g: "signal: killed".
+	DetectResourceFailures
+	// Test packages that failed to compile.
+	DetectBuildFailures
+
+	AllDetectors = DetectAssertions | DetectTimeouts | DetectDataraces | DetectCrashes | DetectLeaks |
+		DetectFuzz | DetectResourceFailures | DetectBuildFailures
+)
+
+// Returns true if all of the `detectors` are in the set.
+func (set DetectorSet) Has(detectors DetectorSet) bool {
+	return set&detectors == detectors
+}
+
+// Module prefixes of our own code. Frames in these modules are highlighted in goroutine summaries
+// and identify dataraces and goroutine leaks.
+var DefaultOwnModulePrefixes = []string{"go.viam.com/", "github.com/viamrobotics/"}
+
+type Options struct {
+	// The failure detectors to run. Zero runs `AllDetectors`.
+	Detectors DetectorSet
+	// Assertions logged by tests whose name contains any of these are ignored. E.g: tests that log
+	// expected/actual values without failing.
+	IgnoreAssertionsIn []string
+	// Functions starting with any of these are our own code. Nil uses `DefaultOwnModulePrefixes`.
+	OwnModulePrefixes []string
+	// If set, called with details about how the log was parsed. E.g: which line started a timeout.
+	Debugf func(format string, args ...any)
+}
+
+func (options Options) detectors() DetectorSet {
+	if options.Detectors == 0 {
+		return AllDetectors
+	}
+
+	return options.Detectors
+}
+
+func (options Options) ownModulePrefixes() []string {
+	if options.OwnModulePrefixes == nil {
+		return DefaultOwnModulePrefixes
+	}
+
+	return options.OwnModulePrefixes
+}
+
+func (options Options) ignoresAssertions(test string) bool {
+	for _, ignored := range options.IgnoreAssertionsIn {
+		if strings.Contains(test, ignored) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (options Options) debugf(format string, args ...any) {
+	if options.Debugf != nil {
+		options.Debugf(format, args...)
+	}
+}
+
+// Used for debug output that is not worth a format string.
+func (options Options) debugln(args ...any) {
+	if options.Debugf != nil {
+		options.Debugf("%v", strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	}
+}
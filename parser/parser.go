@@ -0,0 +1,743 @@
+// Package parser parses go test logs (`go test -json`), JUnit XML and Jest/Vitest JSON reports
+// into classified test failures. E.g:
+//
+//	result, err := parser.Parse(ctx, logFile, parser.Options{})
+//	for _, fqTest := range result.TestFailures {
+//		if timeout := result.Timeouts[fqTest]; timeout != nil {
+//			...
+//		}
+//	}
+//
+// Parsing has no side effects. Lines that cannot be parsed are counted in the result's
+// `Diagnostics` rather than failing the parse.
+package parser
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Removes white-space from the end of a string
+func trimRightSpace(str string) string {
+	return strings.TrimRightFunc(str, unicode.IsSpace)
+}
+
+// The classified failures and the lifecycle of every test in a test log.
+type Result struct {
+	Assertions    map[FQTest][]AssertionFailure
+	Dataraces     map[FQTest]*DataraceFailure
+	RuntimeErrors map[FQTest]*CrashFailure
+	// Panics (other than runtime errors) and go fatal errors.
+	Crashes  map[FQTest]*CrashFailure
+	Timeouts map[FQTest]*TimeoutFailure
+	// Goroutines leaked by a test, or by a package's tests as a whole.
+	Leaks map[FQTest]*LeakFailure
+	// Fuzz tests that found a failing input.
+	Fuzz map[FQTest]*FuzzFailure
+	// Failed Jest or Vitest tests.
+	JSFailures map[FQTest]*JSFailure
+	// Keyed by the package whose test binary was killed or ran out of resources.
+	ResourceFailures map[FQTest]*ResourceFailure
+	// Keyed by the package that failed to build.
+	BuildFailures map[FQTest]*BuildFailure
+	// Test failures that did not match any of the above categories.
+	UnknownFailures map[FQTest]*UnknownFailure
+	// The `<error>`s of JUnit test cases: unexpected errors, rather than failed assertions. The
+	// tests are also classified as above. E.g: as unknown failures.
+	JUnitErrors map[FQTest][]JUnitResult
+	Logs        map[FQTest][]string
+	// The zap log entries in the `Logs` of each test failure.
+	LogEntries map[FQTest][]*LogEntry
+
+	// The lifecycle of every test and package in the log, including those that passed.
+	Tests    map[FQTest]*TestRecord
+	Packages map[string]*PackageRecord
+	// Skipped tests are not failures.
+	Skips map[FQTest]*SkippedTest
+
+	PackageFailures []TestLogLine
+	TestFailures    []FQTest
+	// Test failures that passed when rerun. Their failure details are kept in the categories above,
+	// but they are not in `TestFailures`.
+	Flakes map[FQTest]*Flake
+
+	// Lines in the log that are not test2json records. E.g: `go: downloading ...`.
+	UntaggedOutput []string
+	Diagnostics    Diagnostics
+}
+
+// Returns true if nothing failed, other than tests that passed on a rerun. See `Flakes`.
+func (result *Result) IsSuccess() bool {
+	flakes := result.Flakes
+	return (numHardFailures(result.Assertions, flakes) +
+		numHardFailures(result.Dataraces, flakes) +
+		numHardFailures(result.Timeouts, flakes) +
+		len(result.PackageFailures) +
+		numHardFailures(result.RuntimeErrors, flakes) +
+		numHardFailures(result.Crashes, flakes) +
+		numHardFailures(result.Leaks, flakes) +
+		numHardFailures(result.Fuzz, flakes) +
+		numHardFailures(result.JSFailures, flakes) +
+		numHardFailures(result.ResourceFailures, flakes) +
+		numHardFailures(result.BuildFailures, flakes) +
+		numHardFailures(result.UnknownFailures, flakes) +
+		len(result.TestFailures)) == 0
+}
+
+// Returns true if the test failure was matched to a specific failure category (e.g: an assertion
+// or a timeout).
+func (result *Result) IsClassified(fqTest FQTest) bool {
+	_, aExists := result.Assertions[fqTest]
+	_, tExists := result.Timeouts[fqTest]
+	_, dExists := result.Dataraces[fqTest]
+	_, rExists := result.RuntimeErrors[fqTest]
+	_, cExists := result.Crashes[fqTest]
+	_, lExists := result.Leaks[fqTest]
+	_, fExists := result.Fuzz[fqTest]
+	_, jExists := result.JSFailures[fqTest]
+	_, sExists := result.ResourceFailures[fqTest]
+	_, bExists := result.BuildFailures[fqTest]
+	return aExists || tExists || dExists || rExists || cExists || lExists || fExists || jExists || sExists || bExists
+}
+
+func NewResult() *Result {
+	return &Result{
+		Assertions:       make(map[FQTest][]AssertionFailure),
+		Dataraces:        make(map[FQTest]*DataraceFailure),
+		RuntimeErrors:    make(map[FQTest]*CrashFailure),
+		Crashes:          make(map[FQTest]*CrashFailure),
+		Leaks:            make(map[FQTest]*LeakFailure),
+		Fuzz:             make(map[FQTest]*FuzzFailure),
+		JSFailures:       make(map[FQTest]*JSFailure),
+		ResourceFailures: make(map[FQTest]*ResourceFailure),
+		BuildFailures:    make(map[FQTest]*BuildFailure),
+		Timeouts:         make(map[FQTest]*TimeoutFailure),
+		UnknownFailures:  make(map[FQTest]*UnknownFailure),
+		JUnitErrors:      make(map[FQTest][]JUnitResult),
+		Logs:             make(map[FQTest][]string),
+		LogEntries:       make(map[FQTest][]*LogEntry),
+		Tests:            make(map[FQTest]*TestRecord),
+		Packages:         make(map[string]*PackageRecord),
+		Skips:            make(map[FQTest]*SkippedTest),
+		Flakes:           make(map[FQTest]*Flake),
+	}
+}
+
+type AssertionFailure struct {
+	Package  string
+	File     string
+	Line     int
+	Expected string
+	Actual   string
+	// When the assertion was logged. Zero if unknown.
+	Time time.Time
+}
+
+func (failure AssertionFailure) ToPrettyString(indent string) string {
+	switch failure.Actual {
+	case "":
+		return fmt.Sprintf("%sFile:     %s/%s:%d\n%sExpected: %v\n",
+			indent, failure.Package, failure.File, failure.Line,
+			indent, failure.Expected)
+	default:
+		return fmt.Sprintf("%sFile:     %s/%s:%d\n%sExpected: %v\n%sActual:   %v",
+			indent, failure.Package, failure.File, failure.Line,
+			indent, failure.Expected,
+			indent, strings.TrimSpace(failure.Actual))
+	}
+}
+
+type DataraceFailure struct {
+	Package string
+	// The test that triggered the races. Empty for races reported after all tests completed.
+	Test     string
+	Races    []*Datarace
+	LogLines []string
+}
+
+type TimeoutFailure struct {
+	Package string
+	// E.g: `10m0s`
+	Duration string
+	// The tests that were running when the timeout fired.
+	RunningTests []RunningTest
+	Goroutines   []*Goroutine
+	LogLines     []string
+}
+
+// The number of trailing log lines kept for a test failure that could not be classified. The
+// reason for the failure is most often found at the end of the test's result.
+const unknownFailureNumLines = 20
+
+type UnknownFailure struct {
+	LogLines []string
+}
+
+func NewUnknownFailure(logs []string) *UnknownFailure {
+	if len(logs) > unknownFailureNumLines {
+		logs = logs[len(logs)-unknownFailureNumLines:]
+	}
+
+	return &UnknownFailure{LogLines: logs}
+}
+
+// E.g: "    ur5e_test.go:384: Expected: nil"
+// E.g: "    gpiostepper_test.go:391: Expected '0' to be between '1' and '20000' or equal to one of them (but it wasn't)!"
+var expectedRe *regexp.Regexp = regexp.MustCompile(
+	`^[[:space:]]*(\w+\.go):(\d+): Expected:? (.+)$`)
+
+// E.g: "        Actual:   'timeout'"
+var actualRe *regexp.Regexp = regexp.MustCompile(
+	`^[[:space:]]*Actual:([^\n]+)$`)
+
+// E.g: "panic: test timed out after 10m0s"
+var startTimeoutRe *regexp.Regexp = regexp.MustCompile(
+	`^panic: test timed out after (.*)$`)
+
+// E.g: "Found 1 data race(s)"
+var lastDataraceLogLineRe *regexp.Regexp = regexp.MustCompile(
+	`Found \d+ data race\(s\)`)
+
+// E.g: "    testing.go:1465: race detected during execution of test"
+var raceDetectedRe *regexp.Regexp = regexp.MustCompile(
+	`^[[:space:]]*testing\.go:\d+: race detected during execution of test$`)
+
+// The digits are matched by a regexp. Values too large for an int are treated as unknown.
+func atoi(digits string) int {
+	ret, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0
+	}
+
+	return ret
+}
+
+// Parses a test2json log, as output by `go test -json` or `gotestsum --jsonfile`.
+func Parse(ctx context.Context, reader io.Reader, options Options) (*Result, error) {
+	return parseTestLog(ctx, newLogLineReader(reader), options)
+}
+
+func parseTestLog(ctx context.Context, lines testLogSource, options Options) (*Result, error) {
+	detectors := options.detectors()
+	ret := NewResult()
+	allTestLogs := make(map[FQTest][]string)
+
+	// We parse log lines one at a time, but the "expected" and "actual" values are on
+	// separate log lines. Keep a buffer of any "expected" log lines missing a partner "actual".
+	halfAssertionFailure := make(map[FQTest]*AssertionFailure)
+
+	// Race reports are buffered per package until the test that triggered them is known. Test
+	// binaries report races for the test that was running with a "race detected during execution of
+	// test" line after the race report.
+	pendingRaces := make(map[string]*DataraceFailure)
+	inRaceReport := make(map[string]bool)
+	addPendingRaces := func(pkg string) {
+		race, exists := pendingRaces[pkg]
+		if !exists {
+			return
+		}
+		delete(pendingRaces, pkg)
+
+		fqTest := TestLogLine{Package: race.Package, Test: race.Test}.ToFQTest()
+		if existing, exists := ret.Dataraces[fqTest]; exists {
+			existing.LogLines = append(existing.LogLines, race.LogLines...)
+		} else {
+			ret.Dataraces[fqTest] = race
+		}
+		ret.TestFailures = append(ret.TestFailures, fqTest)
+	}
+
+	// Compiler output is keyed by the `ImportPath` being built.
+	buildOutputs := make(map[string][]string)
+	buildFailedPackages := make(map[string]bool)
+	addBuildFailure := func(doc TestLogLine) {
+		failedBuild := doc.FailedBuild
+		if failedBuild == "" {
+			failedBuild = doc.Package
+		}
+
+		// All test packages that depend on a package that fails to build fail. File one failure for
+		// the package that failed to build.
+		fqTest := FQTest(importPathToPackage(failedBuild))
+		buildFailure, exists := ret.BuildFailures[fqTest]
+		if !exists {
+			buildFailure = &BuildFailure{
+				Package:  importPathToPackage(failedBuild),
+				Errors:   parseBuildOutput(buildOutputs[failedBuild]),
+				LogLines: buildOutputs[failedBuild],
+			}
+			ret.BuildFailures[fqTest] = buildFailure
+			ret.TestFailures = append(ret.TestFailures, fqTest)
+		}
+		buildFailure.AffectedPackages = append(buildFailure.AffectedPackages, doc.Package)
+	}
+
+	// A crash's log lines are buffered until the package's test binary exits. Only then can the
+	// crash be attributed to a test.
+	pendingCrashes := make(map[string]*CrashFailure)
+	addPendingCrash := func(pkg string) {
+		crash, exists := pendingCrashes[pkg]
+		if !exists {
+			return
+		}
+		delete(pendingCrashes, pkg)
+
+		crash.parseLogLines(options.ownModulePrefixes())
+		crashes := ret.Crashes
+		if crash.IsRuntimeError() {
+			crashes = ret.RuntimeErrors
+		}
+		crashes[crash.ToFQTest()] = crash
+		ret.TestFailures = append(ret.TestFailures, crash.ToFQTest())
+	}
+
+	// A goleak report is buffered until the line following it.
+	pendingLeaks := make(map[FQTest]*LeakFailure)
+	addPendingLeak := func(fqTest FQTest) {
+		leak, exists := pendingLeaks[fqTest]
+		if !exists {
+			return
+		}
+		delete(pendingLeaks, fqTest)
+
+		leak.parseLogLines(options.ownModulePrefixes())
+		ret.Leaks[fqTest] = leak
+		ret.TestFailures = append(ret.TestFailures, fqTest)
+	}
+
+	// The last lines each package printed, in order, and why its test binary died (if known).
+	packageTails := make(map[string][]string)
+	resourceReasons := make(map[string]string)
+	exitStatuses := make(map[string]string)
+	for lines.Next() {
+		doc := lines.Doc()
+		doc.Output = trimRightSpace(doc.Output)
+		ret.recordLifecycle(doc)
+
+		if doc.Action == "build-output" {
+			buildOutputs[doc.ImportPath] = append(buildOutputs[doc.ImportPath], doc.Output)
+			continue
+		}
+
+		// A test binary that goes on to pass or skip did not crash. E.g: a test logged a recovered
+		// panic. Its output is no longer part of a crash.
+		if doc.Action == "pass" || doc.Action == "skip" {
+			delete(pendingCrashes, doc.Package)
+		}
+
+		if doc.Action == "fail" {
+			options.debugf("Found doc.Action=`fail`.\n  Doc:%+v", doc)
+			// All failures are associated with a `Package`. Some (most) failures also are
+			// associated with a `Test`. Exceptions include hangs/timeouts.
+			switch {
+			case doc.Test == "" && (doc.FailedBuild != "" || buildFailedPackages[doc.Package]) &&
+				detectors.Has(DetectBuildFailures):
+				addBuildFailure(doc)
+			case doc.Test == "":
+				addPendingCrash(doc.Package)
+				ret.PackageFailures = append(ret.PackageFailures, doc)
+			default:
+				// We expect test failures to be accompanied with `output` test log lines. But we
+				// double-track them here as the definitive source of truth on whether a test
+				// failed.
+				ret.TestFailures = append(ret.TestFailures, doc.ToFQTest())
+			}
+			continue
+		}
+
+		if doc.Action != "output" {
+			continue
+		}
+		allTestLogs[doc.ToFQTest()] = append(allTestLogs[doc.ToFQTest()], doc.Output)
+		packageTails[doc.Package] = append(packageTails[doc.Package], doc.Output)
+		if tail := packageTails[doc.Package]; len(tail) > 2*resourceFailureNumLines {
+			packageTails[doc.Package] = append([]string{}, tail[len(tail)-resourceFailureNumLines:]...)
+		}
+
+		// E.g: "signal: killed". The CI runner likely ran out of memory.
+		if matches := resourceFailureRe.FindStringSubmatch(doc.Output); len(matches) > 0 &&
+			detectors.Has(DetectResourceFailures) {
+			if _, exists := resourceReasons[doc.Package]; !exists {
+				resourceReasons[doc.Package] = matches[1]
+			}
+		}
+		if exitStatusRe.MatchString(doc.Output) && detectors.Has(DetectResourceFailures) {
+			exitStatuses[doc.Package] = doc.Output
+		}
+
+		// E.g: "FAIL\tgo.viam.com/rdk/robot/impl [build failed]". Go versions prior to 1.24 do not
+		// emit `build-output` actions, nor mark the package `fail` with `FailedBuild`.
+		if buildFailedRe.MatchString(doc.Output) {
+			buildFailedPackages[doc.Package] = true
+			continue
+		}
+
+		if matches := expectedRe.FindStringSubmatch(doc.Output); len(matches) > 0 &&
+			detectors.Has(DetectAssertions) {
+			if options.ignoresAssertions(doc.Test) {
+				continue
+			}
+			options.debugf("Found `expected`: %v\n  Adding half-assertion for: `%v`\n  %+v",
+				strings.TrimSpace(doc.Output), doc.ToFQTest(), doc)
+			// E.g: an assertion message that itself starts with `Expected`. Keep the earlier
+			// assertion without its `Actual`.
+			if halfAssertion, exists := halfAssertionFailure[doc.ToFQTest()]; exists {
+				ret.Assertions[doc.ToFQTest()] = append(ret.Assertions[doc.ToFQTest()], *halfAssertion)
+			}
+
+			halfAssertionFailure[doc.ToFQTest()] = &AssertionFailure{
+				Package:  doc.Package,
+				File:     matches[1],
+				Line:     atoi(matches[2]),
+				Expected: matches[3],
+				Time:     doc.ParsedTime(),
+			}
+			continue
+		}
+
+		// An `Actual:` without a preceding `Expected:` is plain output. E.g: an assertion reprinted
+		// in a JUnit failure message.
+		if matches := actualRe.FindStringSubmatch(doc.Output); len(matches) > 0 &&
+			halfAssertionFailure[doc.ToFQTest()] != nil {
+			options.debugf("Found `actual`: %v\n  Adding Assertion for: `%v`", doc.Output, doc.ToFQTest())
+			failure := halfAssertionFailure[doc.ToFQTest()]
+			failure.Actual = matches[1]
+			ret.Assertions[doc.ToFQTest()] = append(ret.Assertions[doc.ToFQTest()], *failure)
+			ret.TestFailures = append(ret.TestFailures, doc.ToFQTest())
+			delete(halfAssertionFailure, doc.ToFQTest())
+			continue
+		}
+
+		// timeout stack traces can interleave with output from different tests. Keep a buffer for
+		// all remaining log lines for the test.
+		if startTimeoutRe.MatchString(doc.Output) && detectors.Has(DetectTimeouts) {
+			options.debugln("Found timeout:", doc.Output)
+			ret.Timeouts[doc.ToFQTest()] = &TimeoutFailure{
+				Package:  doc.Package,
+				Duration: startTimeoutRe.FindStringSubmatch(doc.Output)[1],
+				LogLines: []string{doc.Output},
+			}
+			ret.TestFailures = append(ret.TestFailures, doc.ToFQTest())
+			continue
+		}
+
+		if timeoutFailure, exists := ret.Timeouts[doc.ToFQTest()]; exists {
+			timeoutFailure.LogLines = append(timeoutFailure.LogLines, doc.Output)
+			continue
+		}
+
+		if doc.Output == "WARNING: DATA RACE" && detectors.Has(DetectDataraces) {
+			options.debugln("Found data race. Package:", doc.Package, " FQTest:", doc.ToFQTest())
+			options.debugln(doc.Output)
+			if _, exists := pendingRaces[doc.Package]; !exists {
+				pendingRaces[doc.Package] = &DataraceFailure{
+					Package: doc.Package,
+					Test:    doc.Test,
+				}
+			}
+			pendingRaces[doc.Package].LogLines = append(pendingRaces[doc.Package].LogLines, doc.Output)
+			inRaceReport[doc.Package] = true
+			continue
+		}
+
+		// Race report lines are printed by the race detector, not the test. They need not be
+		// associated with the test that triggered the race.
+		if inRaceReport[doc.Package] {
+			pendingRaces[doc.Package].LogLines = append(pendingRaces[doc.Package].LogLines, doc.Output)
+			if doc.Output == raceReportDelimiter {
+				inRaceReport[doc.Package] = false
+			}
+			continue
+		}
+
+		// E.g: "    testing.go:1465: race detected during execution of test"
+		if raceDetectedRe.MatchString(doc.Output) && doc.Test != "" {
+			if race, exists := pendingRaces[doc.Package]; exists {
+				race.Test = doc.Test
+			}
+			addPendingRaces(doc.Package)
+			continue
+		}
+
+		// E.g: "Found 1 data race(s)". Races reported after all tests have completed are attributed
+		// to the package.
+		if lastDataraceLogLineRe.MatchString(doc.Output) {
+			addPendingRaces(doc.Package)
+			continue
+		}
+
+		if leak, exists := pendingLeaks[doc.ToFQTest()]; exists {
+			if !isLeakReportEnd(doc.Output) {
+				leak.LogLines = append(leak.LogLines, doc.Output)
+				continue
+			}
+			addPendingLeak(doc.ToFQTest())
+		}
+
+		if fuzzInputWrittenRe.MatchString(doc.Output) && detectors.Has(DetectFuzz) {
+			fuzz := newFuzzFailure(doc)
+			ret.Fuzz[fuzz.ToFQTest()] = fuzz
+			ret.TestFailures = append(ret.TestFailures, fuzz.ToFQTest())
+			continue
+		}
+
+		// E.g: "goleak: Errors on successful test run: found unexpected goroutines:"
+		if strings.Contains(doc.Output, leakStartMarker) && detectors.Has(DetectLeaks) {
+			options.debugln("Found goroutine leak. Package:", doc.Package, " FQTest:", doc.ToFQTest())
+			pendingLeaks[doc.ToFQTest()] = newLeakFailure(doc)
+			continue
+		}
+
+		// Everything a crashed test binary prints after the panic or fatal error is part of the
+		// crash. E.g: the goroutine dump.
+		if crash, exists := pendingCrashes[doc.Package]; exists {
+			crash.LogLines = append(crash.LogLines, doc.Output)
+			continue
+		}
+
+		if startCrashRe.MatchString(doc.Output) && detectors.Has(DetectCrashes) {
+			options.debugln("Found crash. Package:", doc.Package, " FQTest:", doc.ToFQTest())
+			options.debugln(doc.Output)
+			pendingCrashes[doc.Package] = newCrashFailure(doc)
+			continue
+		}
+	}
+
+	if err := lines.Err(); err != nil {
+		return ret, err
+	}
+	ret.Diagnostics = lines.Diagnostics()
+	ret.UntaggedOutput = lines.UntaggedOutput()
+	if ret.Diagnostics.NumSkippedLines() > 0 {
+		options.debugln("Parse diagnostics:", ret.Diagnostics.ToPrettyString())
+	}
+
+	// E.g: the test binary crashed before reporting which test raced.
+	for pkg := range pendingRaces {
+		addPendingRaces(pkg)
+	}
+	// E.g: the log was cut off before the package's final `fail`.
+	for pkg := range pendingCrashes {
+		addPendingCrash(pkg)
+	}
+	for test := range pendingLeaks {
+		addPendingLeak(test)
+	}
+
+	for test, expectedMsg := range halfAssertionFailure {
+		options.debugf("Adding half assertion to full. Test: %v ExpectedMsg: %+v", test, *expectedMsg)
+		ret.Assertions[test] = append(ret.Assertions[test], *expectedMsg)
+	}
+
+	for test := range ret.Assertions {
+		options.debugln("Saving logs for assertion failure:", test)
+		ret.Logs[test] = allTestLogs[test]
+	}
+	// The timeout panic is typically not associated with a test. Attribute those timeouts to the
+	// test that hung, if it can be determined.
+	packageTimeouts := make(map[FQTest]*TimeoutFailure)
+	for test, timeout := range ret.Timeouts {
+		timeout.parseLogLines(options.ownModulePrefixes())
+		if test == FQTest(timeout.Package) && timeout.HungTest() != "" {
+			packageTimeouts[test] = timeout
+		}
+	}
+	for test, timeout := range packageTimeouts {
+		hungFQTest := TestLogLine{Package: timeout.Package, Test: timeout.HungTest()}.ToFQTest()
+		options.debugln("Attributing package timeout:", test, "to test:", hungFQTest)
+		delete(ret.Timeouts, test)
+		ret.Timeouts[hungFQTest] = timeout
+		for idx, testFailure := range ret.TestFailures {
+			if testFailure == test {
+				ret.TestFailures[idx] = hungFQTest
+			}
+		}
+		// The goroutine dump is logged at the package level. Keep it with the test's logs.
+		allTestLogs[hungFQTest] = append(allTestLogs[hungFQTest], timeout.LogLines...)
+	}
+	for test := range ret.Timeouts {
+		options.debugln("Saving logs for timeout failure:", test)
+		ret.Logs[test] = allTestLogs[test]
+	}
+	for test, datarace := range ret.Dataraces {
+		datarace.parseLogLines(options.ownModulePrefixes())
+		options.debugln("Saving logs for datarace failure:", test)
+		ret.Logs[test] = allTestLogs[test]
+	}
+	for test, runtimeError := range ret.RuntimeErrors {
+		options.debugln("Saving logs for runtime error failure:", test)
+		ret.Logs[test] = allTestLogs[test]
+		if len(ret.Logs[test]) == 0 {
+			ret.Logs[test] = runtimeError.LogLines
+		}
+	}
+	for test, buildFailure := range ret.BuildFailures {
+		options.debugln("Saving logs for build failure:", test)
+		ret.Logs[test] = append(append([]string{}, buildFailure.LogLines...), allTestLogs[test]...)
+	}
+	for test, crash := range ret.Crashes {
+		options.debugln("Saving logs for crash failure:", test)
+		ret.Logs[test] = allTestLogs[test]
+		if len(ret.Logs[test]) == 0 {
+			ret.Logs[test] = crash.LogLines
+		}
+	}
+	for test, fuzz := range ret.Fuzz {
+		options.debugln("Saving logs for fuzz failure:", test)
+		ret.Logs[test] = allTestLogs[test]
+		// The corpus file may be printed outside of the test, e.g: by a later CI step.
+		fuzz.parseLogLines(append(append([]string{}, allTestLogs[test]...), allTestLogs[FQTest(fuzz.Package)]...))
+	}
+	for test, leak := range ret.Leaks {
+		options.debugln("Saving logs for goroutine leak failure:", test)
+		ret.Logs[test] = allTestLogs[test]
+		if len(ret.Logs[test]) == 0 {
+			ret.Logs[test] = leak.LogLines
+		}
+	}
+
+	options.debugln("All failures:", ret.TestFailures)
+
+	// A test binary that exits with `exit status 2` without reporting any failure was most likely
+	// killed. E.g: by the kernel's OOM killer.
+	failedPackages := make(map[string]bool)
+	for _, testFailure := range ret.TestFailures {
+		if ret.IsClassified(testFailure) {
+			failedPackages[ret.PackageOf(testFailure)] = true
+		}
+	}
+	for pkg, exitStatus := range exitStatuses {
+		record := ret.Packages[pkg]
+		if _, exists := resourceReasons[pkg]; !exists && exitStatus == "exit status 2" &&
+			record != nil && record.Status == "fail" && !failedPackages[pkg] {
+			resourceReasons[pkg] = exitStatus
+		}
+	}
+	for pkg, reason := range resourceReasons {
+		options.debugln("Found resource failure. Package:", pkg, "Reason:", reason)
+		tail := packageTails[pkg]
+		if len(tail) > resourceFailureNumLines {
+			tail = tail[len(tail)-resourceFailureNumLines:]
+		}
+		resourceFailure := &ResourceFailure{
+			Package:      pkg,
+			Reason:       reason,
+			RunningTests: ret.unfinishedTests(pkg),
+			LogLines:     tail,
+		}
+		ret.ResourceFailures[resourceFailure.ToFQTest()] = resourceFailure
+		ret.Logs[resourceFailure.ToFQTest()] = tail
+		ret.TestFailures = append(ret.TestFailures, resourceFailure.ToFQTest())
+	}
+
+	ret.suppressParentFailures(allTestLogs)
+
+	// Test failures that do not match a known category (e.g: a plain `--- FAIL` with no recognized
+	// message) are kept as unknown failures such that they still get reported.
+	for _, testFailure := range ret.TestFailures {
+		if ret.IsClassified(testFailure) {
+			continue
+		}
+
+		options.debugln("Unknown test failure:", testFailure)
+		ret.UnknownFailures[testFailure] = NewUnknownFailure(allTestLogs[testFailure])
+		ret.Logs[testFailure] = allTestLogs[testFailure]
+	}
+
+	ret.TestFailures = SortDedupTestFailures(ret.TestFailures)
+	for _, testFailure := range ret.TestFailures {
+		ret.LogEntries[testFailure] = parseLogEntries(ret.Logs[testFailure])
+	}
+	ret.separateRerunFlakes()
+
+	for fqTest, record := range ret.Tests {
+		if record.Status != "skip" {
+			continue
+		}
+
+		ret.Skips[fqTest] = &SkippedTest{
+			Package: record.Package,
+			Name:    record.Name,
+			Reason:  skipReason(allTestLogs[fqTest]),
+		}
+	}
+
+	return ret, nil
+}
+
+func SortDedupTestFailures(failures []FQTest) []FQTest {
+	ret := make([]FQTest, 0)
+	seen := make(map[FQTest]struct{})
+	for _, failure := range failures {
+		if _, exists := seen[failure]; exists {
+			continue
+		}
+
+		seen[failure] = struct{}{}
+		ret = append(ret, failure)
+	}
+
+	sort.Slice(ret, func(left, right int) bool {
+		return ret[left] < ret[right]
+	})
+
+	return ret
+}
+
+type TestLogLine struct {
+	Time string
+	// One of `start`, `run`, `pause`, `cont`, `pass`, `bench`, `fail`, `skip`, `output` or
+	// `attr`. Go 1.24+ also emits `build-output` and `build-fail`.
+	Action  string
+	Package string
+	Output  string
+	Test    string
+	Elapsed float64
+	// Set on `build-output` and `build-fail` actions. E.g: `go.viam.com/rdk/robot/impl
+	// [go.viam.com/rdk/robot/impl.test]`.
+	ImportPath string
+	// Set on a package `fail` when the package's test binary failed to build. The value is the
+	// `ImportPath` of the build that failed.
+	FailedBuild string
+	// Set on `attr` actions (go 1.25+).
+	Key   string
+	Value string
+}
+
+func (testLogLine TestLogLine) ToPackageFailureString() string {
+	return fmt.Sprintf("%v (%vs)", testLogLine.Package, testLogLine.Elapsed)
+}
+
+type FQTest string
+
+func (testLogLine TestLogLine) ToFQTest() FQTest {
+	switch testLogLine.Test {
+	case "":
+		return FQTest(testLogLine.Package)
+	default:
+		return FQTest(fmt.Sprintf("%v.%v", testLogLine.Package, testLogLine.Test))
+	}
+}
+
+// Parses a test2json log, JUnit XML or a Jest/Vitest report. JUnit is recognized by its file
+// name, Jest reports by their contents.
+func ParseFile(ctx context.Context, fileName string, reader io.Reader, options Options) (*Result, error) {
+	if strings.HasSuffix(fileName, ".xml") {
+		return ParseJUnit(ctx, reader, options)
+	}
+
+	buffered := bufio.NewReader(reader)
+	if isJestReport(buffered) {
+		return ParseJest(ctx, buffered, options)
+	}
+
+	return Parse(ctx, buffered, options)
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func assertionLog(pkg, test string) []TestLogLine {
+	output := func(line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+	return []TestLogLine{
+		{Action: "run", Package: pkg, Test: test},
+		output("=== RUN   " + test),
+		output("    arm_test.go:42: Expected: 1"),
+		output("        Actual:   2"),
+		output("--- FAIL: " + test + " (0.00s)"),
+		{Action: "fail", Package: pkg, Test: test},
+		{Action: "fail", Package: pkg},
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	const pkg = "go.viam.com/rdk/components/arm"
+	const fqTest = FQTest(pkg + ".TestArmPosition")
+
+	result, err := Parse(context.Background(), parsertest.LogReader(assertionLog(pkg, "TestArmPosition")...), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assertions := result.Assertions[fqTest]; len(assertions) != 1 || assertions[0].Line != 42 {
+		t.Fatalf("Expected an assertion. Actual: %+v", result.Assertions)
+	}
+
+	// Without the assertion detector, the failure is still reported.
+	result, err = Parse(context.Background(), parsertest.LogReader(assertionLog(pkg, "TestArmPosition")...),
+		Options{Detectors: AllDetectors &^ DetectAssertions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Assertions) != 0 || result.UnknownFailures[fqTest] == nil {
+		t.Fatalf("Expected an unknown failure. Assertions: %v Unknown: %v", result.Assertions, result.UnknownFailures)
+	}
+
+	result, err = Parse(context.Background(), parsertest.LogReader(assertionLog(pkg, "TestArmPosition")...),
+		Options{IgnoreAssertionsIn: []string{"TestArm"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Assertions) != 0 || result.UnknownFailures[fqTest] == nil {
+		t.Fatalf("Expected the assertion to be ignored. Assertions: %v", result.Assertions)
+	}
+
+	debugLines := make([]string, 0)
+	_, err = Parse(context.Background(), parsertest.LogReader(assertionLog(pkg, "TestArmPosition")...), Options{
+		Debugf: func(format string, args ...any) {
+			debugLines = append(debugLines, fmt.Sprintf(format, args...))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(debugLines) == 0 || !strings.HasPrefix(debugLines[0], "Found `expected`") {
+		t.Fatalf("Wrong debug output: %v", debugLines)
+	}
+}
+
+func TestDetectorSet(t *testing.T) {
+	detectors := DetectTimeouts | DetectDataraces
+	if !detectors.Has(DetectTimeouts) || !detectors.Has(DetectTimeouts|DetectDataraces) ||
+		detectors.Has(DetectLeaks) || detectors.Has(DetectTimeouts|DetectLeaks) {
+		t.Fatalf("Wrong detectors: %b", detectors)
+	}
+	if (Options{}).detectors() != AllDetectors {
+		t.Fatalf("Expected the zero options to run all detectors.")
+	}
+}
+
+// An `Expected:` that is not followed by an `Actual:` before the next `Expected:` is kept as an
+// assertion without an actual value.
+func TestRepeatedExpected(t *testing.T) {
+	const pkg = "go.viam.com/rdk/components/arm"
+	output := func(line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: "TestArm", Output: line + "\n"}
+	}
+	result, err := Parse(context.Background(), parsertest.LogReader(
+		output("    arm_test.go:10: Expected: 1"),
+		output("    arm_test.go:20: Expected: 3"),
+		output("        Actual:   4"),
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestArm"},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertions := result.Assertions[FQTest(pkg+".TestArm")]
+	if len(assertions) != 2 || assertions[0].Line != 10 || assertions[0].Actual != "" ||
+		assertions[1].Line != 20 || strings.TrimSpace(assertions[1].Actual) != "4" {
+		t.Fatalf("Wrong assertions: %+v", assertions)
+	}
+}
@@ -0,0 +1,21 @@
+// Package parsertest provides helpers for tests of code that parses test2json logs.
+package parsertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Returns a reader over the test2json encoding of the input `lines`. E.g: `parser.TestLogLine`s.
+func LogReader[Line any](lines ...Line) io.Reader {
+	buf := bytes.NewBuffer(nil)
+	encoder := json.NewEncoder(buf)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			panic(err)
+		}
+	}
+
+	return buf
+}
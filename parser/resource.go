@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A test binary that was killed or ran out of resources. E.g: the CI runner ran out of memory.
+// These are CI infrastructure problems rather than test bugs.
+type ResourceFailure struct {
+	Package string
+	// E.g: `signal: killed`, `exit status 2` or `race: limit on 8128 simultaneously alive
+	// goroutines is exceeded`.
+	Reason string
+	// The tests that had not finished when the binary died.
+	RunningTests []string
+	// The last lines the package printed.
+	LogLines []string
+}
+
+// E.g: "signal: killed"
+// E.g: "race: limit on 8128 simultaneously alive goroutines is exceeded, dying"
+var resourceFailureRe *regexp.Regexp = regexp.MustCompile(
+	`^(signal: killed|race: limit on \d+ simultaneously alive goroutines is exceeded)`)
+
+// E.g: "exit status 2"
+var exitStatusRe *regexp.Regexp = regexp.MustCompile(
+	`^exit status (\d+)$`)
+
+// The number of trailing package log lines kept for a resource failure.
+const resourceFailureNumLines = 30
+
+// Returns the package a (classified) test failure belongs to. That is the package of the test's
+// record, e.g: the JUnit suite or Jest file, else the package the failure was found in. Test names
+// can contain dots, so the package is never derived from the name.
+func (result *Result) PackageOf(fqTest FQTest) string {
+	if record, exists := result.Tests[fqTest]; exists {
+		return record.Package
+	}
+
+	switch {
+	case len(result.Assertions[fqTest]) > 0:
+		return result.Assertions[fqTest][0].Package
+	case result.Timeouts[fqTest] != nil:
+		return result.Timeouts[fqTest].Package
+	case result.Dataraces[fqTest] != nil:
+		return result.Dataraces[fqTest].Package
+	case result.RuntimeErrors[fqTest] != nil:
+		return result.RuntimeErrors[fqTest].Package
+	case result.Crashes[fqTest] != nil:
+		return result.Crashes[fqTest].Package
+	case result.Leaks[fqTest] != nil:
+		return result.Leaks[fqTest].Package
+	case result.Fuzz[fqTest] != nil:
+		return result.Fuzz[fqTest].Package
+	case result.JSFailures[fqTest] != nil:
+		return result.JSFailures[fqTest].Package
+	case result.ResourceFailures[fqTest] != nil:
+		return result.ResourceFailures[fqTest].Package
+	case result.BuildFailures[fqTest] != nil:
+		return result.BuildFailures[fqTest].Package
+	}
+
+	// Unclassified failures not attributed to a test are keyed by their package.
+	return string(fqTest)
+}
+
+// Returns the tests of the package that never finished, sorted by name.
+func (result *Result) unfinishedTests(pkg string) []string {
+	ret := make([]string, 0)
+	for _, record := range result.Tests {
+		if record.Package == pkg && !record.IsFinished() {
+			ret = append(ret, record.Name)
+		}
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+func (failure *ResourceFailure) ToFQTest() FQTest {
+	return FQTest(failure.Package)
+}
+
+// E.g:
+//
+//	signal: killed
+//	Running tests: TestMoveOnGlobe, TestMoveOnGlobe/go_around_an_obstacle
+func (failure *ResourceFailure) ToPrettyString(indent string) string {
+	ret := indent + failure.Reason
+	if len(failure.RunningTests) > 0 {
+		ret = fmt.Sprintf("%v\n%sRunning tests: %v", ret, indent, strings.Join(failure.RunningTests, ", "))
+	}
+
+	return ret
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestResourceFailure(t *testing.T) {
+	const killedPkg = "go.viam.com/rdk/services/motion/builtin"
+	const silentPkg = "go.viam.com/rdk/motionplan"
+	const panicPkg = "go.viam.com/rdk/robot/impl"
+	output := func(pkg, test, line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+
+	logs, err := Parse(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "run", Package: killedPkg, Test: "TestMoveOnGlobe"},
+		output(killedPkg, "TestMoveOnGlobe", "=== RUN   TestMoveOnGlobe"),
+		TestLogLine{Action: "run", Package: killedPkg, Test: "TestMoveOnGlobe/go_around_an_obstacle"},
+		output(killedPkg, "TestMoveOnGlobe/go_around_an_obstacle", "=== RUN   TestMoveOnGlobe/go_around_an_obstacle"),
+		output(killedPkg, "", "signal: killed"),
+		output(killedPkg, "", "FAIL\tgo.viam.com/rdk/services/motion/builtin\t600.1s"),
+		TestLogLine{Action: "fail", Package: killedPkg},
+
+		// The test binary printed nothing but its exit status.
+		output(silentPkg, "", "exit status 2"),
+		output(silentPkg, "", "FAIL\tgo.viam.com/rdk/motionplan\t12.3s"),
+		TestLogLine{Action: "fail", Package: silentPkg},
+
+		// A panic also exits with status 2, but is not a resource failure.
+		TestLogLine{Action: "run", Package: panicPkg, Test: "TestRobotClose"},
+		output(panicPkg, "TestRobotClose", "panic: close of closed channel"),
+		output(panicPkg, "", "exit status 2"),
+		output(panicPkg, "", "FAIL\tgo.viam.com/rdk/robot/impl\t0.1s"),
+		TestLogLine{Action: "fail", Package: panicPkg},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	killed := logs.ResourceFailures[FQTest(killedPkg)]
+	if killed == nil || killed.Reason != "signal: killed" || len(killed.RunningTests) != 2 ||
+		killed.RunningTests[1] != "TestMoveOnGlobe/go_around_an_obstacle" {
+		t.Fatalf("Wrong resource failure for the killed package: %+v", killed)
+	}
+	if len(killed.LogLines) != 4 {
+		t.Fatalf("Expected the package's last lines. Actual: %v", killed.LogLines)
+	}
+
+	if silent := logs.ResourceFailures[FQTest(silentPkg)]; silent == nil || silent.Reason != "exit status 2" {
+		t.Fatalf("Wrong resource failure for the silent package: %+v", silent)
+	}
+
+	if _, exists := logs.ResourceFailures[FQTest(panicPkg)]; exists || len(logs.Crashes) != 1 {
+		t.Fatalf("Expected a crash, not a resource failure. Resources: %v Crashes: %v",
+			logs.ResourceFailures, logs.Crashes)
+	}
+}
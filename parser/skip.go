@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A test that was skipped, e.g: with `t.Skip`.
+type SkippedTest struct {
+	Package string
+	Name    string
+	// The skip message. E.g: `flaky, see RSDK-1234`. Empty if the test did not give one.
+	Reason string
+}
+
+// E.g: "    motion_test.go:42: flaky, see RSDK-1234"
+var skipMessageRe *regexp.Regexp = regexp.MustCompile(
+	`^\s*\S+?(_test)?\.go:\d+: (.+)$`)
+
+// Returns the skip message in the test's logs. `t.Skip` logs the message right before the
+// `--- SKIP` line, or right after it without `-v`. The message closest to the `--- SKIP` line
+// from a `_test.go` file wins. Else the closest message from a helper, e.g: `test_helper.go`. Log
+// entries, e.g: zap logs from a test logger, are not skip messages.
+func skipReason(logs []string) string {
+	// The candidates before the `--- SKIP` line are overwritten, such that the last one is kept.
+	// The candidates after it are only set once.
+	var testBefore, helperBefore, testAfter, helperAfter string
+	skipped := false
+	for _, line := range logs {
+		if strings.HasPrefix(strings.TrimSpace(line), "--- SKIP") {
+			skipped = true
+			continue
+		}
+
+		matches := skipMessageRe.FindStringSubmatch(line)
+		if len(matches) == 0 {
+			continue
+		}
+		if _, isLogEntry := parseLogEntry(line); isLogEntry {
+			continue
+		}
+
+		isTestFile, message := matches[1] != "", matches[2]
+		switch {
+		case !skipped && isTestFile:
+			testBefore = message
+		case !skipped:
+			helperBefore = message
+		case isTestFile && testAfter == "":
+			testAfter = message
+		case !isTestFile && helperAfter == "":
+			helperAfter = message
+		}
+	}
+
+	for _, candidate := range []string{testBefore, testAfter, helperBefore, helperAfter} {
+		if candidate != "" {
+			return candidate
+		}
+	}
+
+	return ""
+}
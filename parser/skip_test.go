@@ -0,0 +1,40 @@
+package parser
+
+import "testing"
+
+func TestSkipReason(t *testing.T) {
+	for _, testCase := range []struct {
+		logs   []string
+		reason string
+	}{
+		{[]string{
+			"=== RUN   TestMoveOnGlobe",
+			"    motion_test.go:42: flaky, see RSDK-1234",
+			"--- SKIP: TestMoveOnGlobe (0.00s)",
+		}, "flaky, see RSDK-1234"},
+		// Without `-v` the message follows the `--- SKIP` line.
+		{[]string{
+			"--- SKIP: TestMoveOnGlobe (0.00s)",
+			"    motion_test.go:42: flaky, see RSDK-1234",
+			"    motion_test.go:50: unrelated",
+		}, "flaky, see RSDK-1234"},
+		// Zap logs are ignored. Messages from test files are preferred.
+		{[]string{
+			"    motion_test.go:42: flaky, see RSDK-1234",
+			"    logger.go:130: 2023-08-01T20:15:01.677Z\tINFO\tmotion/builtin.go:88\tstopping",
+			"    motion_test.go:60: {\"level\":\"info\",\"ts\":1690920901.677,\"msg\":\"stopping\"}",
+			"    helpers.go:12: not a skip message",
+			"--- SKIP: TestMoveOnGlobe (0.00s)",
+		}, "flaky, see RSDK-1234"},
+		// Skip helpers in other files log from their own file.
+		{[]string{
+			"    test_helper.go:177: set environment variable \"VIAM_DEBUG\" to run this test",
+			"--- SKIP: TestDepthSourceGripper (0.00s)",
+		}, "set environment variable \"VIAM_DEBUG\" to run this test"},
+		{[]string{"--- SKIP: TestMoveOnGlobe (0.00s)"}, ""},
+	} {
+		if reason := skipReason(testCase.logs); reason != testCase.reason {
+			t.Fatalf("Wrong skip reason: `%v` Expected: `%v` Logs: %q", reason, testCase.reason, testCase.logs)
+		}
+	}
+}
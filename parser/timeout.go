@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A test that was running when a test binary timed out.
+type RunningTest struct {
+	// E.g: `TestMoveOnGlobe/go_around_an_obstacle`
+	Name string
+	// E.g: `9m58s`
+	Duration string
+}
+
+// E.g: "running tests:"
+const runningTestsHeader = "running tests:"
+
+// E.g: "\tTestMoveOnGlobe/go_around_an_obstacle (9m58s)"
+var runningTestRe *regexp.Regexp = regexp.MustCompile(
+	`^\t(\S+) \((\S+)\)$`)
+
+// Parses the `running tests:` section go 1.20+ prints after a test timeout panic. E.g:
+//
+//	panic: test timed out after 10m0s
+//	running tests:
+//		TestStartWaypoint (10m0s)
+//		TestStartWaypoint/test_observed_obstacle (9m58s)
+func parseRunningTests(lines []string) []RunningTest {
+	ret := make([]RunningTest, 0)
+	inSection := false
+	for _, line := range lines {
+		if line == runningTestsHeader {
+			inSection = true
+			continue
+		}
+
+		if !inSection {
+			continue
+		}
+
+		matches := runningTestRe.FindStringSubmatch(line)
+		if len(matches) == 0 {
+			break
+		}
+		ret = append(ret, RunningTest{Name: matches[1], Duration: matches[2]})
+	}
+
+	return ret
+}
+
+// E.g: "go.viam.com/rdk/services/navigation/builtin.TestStartWaypoint.func5.1"
+var testFuncRe *regexp.Regexp = regexp.MustCompile(
+	`^(.+)\.((?:Test|Fuzz)[^./]*)(?:\.func\d+)*(?:\.\d+)*$`)
+
+// Infers the running tests from a goroutine dump for go versions that do not print a
+// `running tests:` section. Each goroutine running a test has a `testing.tRunner` frame below the
+// test function. Only top-level test names can be recovered this way.
+func runningTestsFromGoroutines(pkg string, goroutines []*Goroutine) []RunningTest {
+	minutes := make(map[string]int)
+	names := make([]string, 0)
+	for _, goroutine := range goroutines {
+		if !goroutine.HasFrame("testing.tRunner") {
+			continue
+		}
+
+		for _, frame := range goroutine.Frames {
+			matches := testFuncRe.FindStringSubmatch(frame.Func)
+			if len(matches) == 0 || matches[1] != pkg {
+				continue
+			}
+
+			name := matches[2]
+			if _, exists := minutes[name]; !exists {
+				names = append(names, name)
+			}
+			minutes[name] = max(minutes[name], goroutine.Minutes)
+			break
+		}
+	}
+
+	ret := make([]RunningTest, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, RunningTest{Name: name, Duration: fmt.Sprintf("%dm", minutes[name])})
+	}
+
+	return ret
+}
+
+// Fills in the `Goroutines` and `RunningTests` from the timeout's log lines.
+func (timeout *TimeoutFailure) parseLogLines(ownModulePrefixes []string) {
+	timeout.Goroutines = parseGoroutineDump(timeout.LogLines, ownModulePrefixes)
+	timeout.RunningTests = parseRunningTests(timeout.LogLines)
+	if len(timeout.RunningTests) == 0 {
+		timeout.RunningTests = runningTestsFromGoroutines(timeout.Package, timeout.Goroutines)
+	}
+}
+
+// Returns the running test most likely to have hung. See `longestRunningLeafTest`.
+func (timeout *TimeoutFailure) HungTest() string {
+	return longestRunningLeafTest(timeout.RunningTests)
+}
+
+// Returns the longest running test that has no running subtests. Returns the empty string if no
+// running tests are known.
+func longestRunningLeafTest(runningTests []RunningTest) string {
+	var ret string
+	var retDuration time.Duration
+	for _, test := range runningTests {
+		isParent := false
+		for _, other := range runningTests {
+			if strings.HasPrefix(other.Name, test.Name+"/") {
+				isParent = true
+				break
+			}
+		}
+		if isParent {
+			continue
+		}
+
+		// Durations inferred from goroutine dumps are whole minutes, e.g: `9m`.
+		duration, err := time.ParseDuration(test.Duration)
+		if err != nil {
+			duration = 0
+		}
+		if ret == "" || duration > retDuration {
+			ret, retDuration = test.Name, duration
+		}
+	}
+
+	return ret
+}
+
+// Returns the timeout panic along with the tests that were running at the time. E.g:
+//
+//	panic: test timed out after 10m0s
+//	running tests:
+//		TestStartWaypoint (10m0s)
+func (timeout *TimeoutFailure) ToPrettyString() string {
+	lines := []string{timeout.LogLines[0]}
+	if len(timeout.RunningTests) > 0 {
+		lines = append(lines, runningTestsHeader)
+		for _, test := range timeout.RunningTests {
+			lines = append(lines, fmt.Sprintf("\t%v (%v)", test.Name, test.Duration))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Returns the goroutine dump grouped by identical stacks. See `summarizeGoroutines`.
+func (timeout *TimeoutFailure) GoroutineSummary(indent string) string {
+	return summarizeGoroutines(timeout.Goroutines, indent)
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestTimeoutAttribution(t *testing.T) {
+	const pkg = "go.viam.com/rdk/services/navigation/builtin"
+	packageOutput := func(output string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Output: output + "\n"}
+	}
+
+	// Go 1.20+ lists the running tests after the timeout panic.
+	output, err := Parse(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "output", Package: pkg, Test: "TestStartWaypoint", Output: "=== RUN   TestStartWaypoint\n"},
+		packageOutput("panic: test timed out after 10m0s"),
+		packageOutput("running tests:"),
+		packageOutput("\tTestStartWaypoint (10m0s)"),
+		packageOutput("\tTestStartWaypoint/test_observed_obstacle (9m58s)"),
+		packageOutput("\tTestStopWaypoint (1m2s)"),
+		packageOutput(""),
+		packageOutput("goroutine 103 [running]:"),
+		packageOutput("FAIL\t"+pkg+"\t600.208s"),
+		TestLogLine{Action: "fail", Package: pkg, Elapsed: 600.208},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const hungTest = FQTest(pkg + ".TestStartWaypoint/test_observed_obstacle")
+	timeout, exists := output.Timeouts[hungTest]
+	if !exists {
+		t.Fatalf("Expected timeout for %v. Timeouts: %+v", hungTest, output.Timeouts)
+	}
+	if len(timeout.RunningTests) != 3 || timeout.Duration != "10m0s" {
+		t.Fatalf("Wrong timeout: %+v", timeout)
+	}
+	if len(output.TestFailures) != 1 || output.TestFailures[0] != hungTest {
+		t.Fatalf("Wrong test failures: %v", output.TestFailures)
+	}
+
+	// Older go versions only print the goroutine dump.
+	output, err = Parse(context.Background(), parsertest.LogReader(
+		packageOutput("panic: test timed out after 10m0s"),
+		packageOutput(""),
+		packageOutput("goroutine 64 [chan receive, 9 minutes]:"),
+		packageOutput("testing.(*T).Run(0xc000282ea0, {0x299c94d, 0x28}, 0xc000776140)"),
+		packageOutput("\t/usr/lib/go-1.19/src/testing/testing.go:1494 +0x789"),
+		packageOutput(pkg+".TestStartWaypoint(0xc000282ea0)"),
+		packageOutput("\t/__w/rdk/rdk/services/navigation/builtin/builtin_test.go:236 +0x197a"),
+		packageOutput("testing.tRunner(0xc000282ea0, 0x2ea05a8)"),
+		packageOutput("\t/usr/lib/go-1.19/src/testing/testing.go:1446 +0x217"),
+		packageOutput("created by testing.(*T).Run"),
+		packageOutput("\t/usr/lib/go-1.19/src/testing/testing.go:1493 +0x75e"),
+		TestLogLine{Action: "fail", Package: pkg, Elapsed: 600.208},
+	), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeout, exists = output.Timeouts[FQTest(pkg+".TestStartWaypoint")]
+	if !exists {
+		t.Fatalf("Expected timeout for TestStartWaypoint. Timeouts: %+v", output.Timeouts)
+	}
+	if len(timeout.RunningTests) != 1 || timeout.RunningTests[0].Duration != "9m" {
+		t.Fatalf("Wrong running tests: %+v", timeout.RunningTests)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// E.g: `/api/v1/runs/rdk/5859328480`.
+var runReportPathRe *regexp.Regexp = regexp.MustCompile(
+	`^/api/v1/runs/([^/]+)/(\d+)$`)
+
+// Serves the `RunReport` of a github run:
+//
+//	GET /api/v1/runs/<repo>/<run id>
+func (server *BFServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	matches := runReportPathRe.FindStringSubmatch(request.URL.Path)
+	if len(matches) == 0 {
+		writeAPIError(writer, http.StatusNotFound, fmt.Errorf("Unknown path: `%v`", request.URL.Path))
+		return
+	}
+	if request.Method != http.MethodGet {
+		writeAPIError(writer, http.StatusMethodNotAllowed, fmt.Errorf("Unsupported method: `%v`", request.Method))
+		return
+	}
+
+	repo := matches[1]
+	if !isTestedRepo(repo) {
+		writeAPIError(writer, http.StatusNotFound, fmt.Errorf("Unknown repo: `%v`", repo))
+		return
+	}
+	runId, err := strconv.ParseInt(matches[2], 10, 64)
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	failures, err := server.runFailures(request.Context(), repo, runId)
+	if err != nil {
+		writeAPIError(writer, apiErrorStatus(err), err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(NewRunReport(repo, runId, failures)); err != nil {
+		fmt.Println("Error writing run report:", err)
+	}
+}
+
+// Returns the status to reply with when the run's failures cannot be fetched. A run that github
+// does not know of is not found, any other error is github's.
+func apiErrorStatus(err error) int {
+	var githubErr *github.ErrorResponse
+	if errors.As(err, &githubErr) && githubErr.Response != nil &&
+		githubErr.Response.StatusCode == http.StatusNotFound {
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadGateway
+}
+
+// E.g: `{"error": "Unknown path: ..."}`.
+func writeAPIError(writer http.ResponseWriter, status int, err error) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(map[string]string{"error": err.Error()})
+}
+
+// Returns the test failures of all jobs in the run.
+func (server *BFServer) githubRunFailures(ctx context.Context, repo string, runId int64) ([]Failure, error) {
+	return GithubRunToFailedTests(ctx, server.client, repo, runId, int64(0), false)
+}
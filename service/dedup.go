@@ -2,39 +2,100 @@ package service
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/andygrunwald/go-jira"
 	"github.com/viamrobotics/bfserver/util"
 )
 
+// Returns the failure's category, the summary its ticket is filed under and its signatures. See
+// `isDuplicateTicket`. Categories are checked in the order of the `Category*` constants, e.g: a
+// fuzz target's assertion is part of the fuzz failure. Failures that match no category are
+// unclassified.
+func (output *Output) classify(fqTest FQTest) (category, summary string, signatures []string) {
+	// Dynamic subtest names (e.g: ports) would otherwise defeat summary-equality dedup.
+	summaryTest := TestNameNormalizer.Normalize(fqTest)
+	if fuzz := output.Fuzz[fqTest]; fuzz != nil {
+		return CategoryFuzz, fmt.Sprintf("Test Fuzz Failure: %v", summaryTest), nil
+	} else if jsFailure := output.JSFailures[fqTest]; jsFailure != nil {
+		return CategoryJS, fmt.Sprintf("Test Failure: %v", summaryTest), nil
+	} else if assertions := output.Assertions[fqTest]; len(assertions) > 0 {
+		return CategoryAssertion, fmt.Sprintf("Test Failure: %v", summaryTest), nil
+	} else if timeout := output.Timeouts[fqTest]; timeout != nil {
+		return CategoryTimeout, fmt.Sprintf("Test Timeout: %v", summaryTest), nil
+	} else if datarace := output.Dataraces[fqTest]; datarace != nil {
+		return CategoryDatarace, fmt.Sprintf("Test Datarace: %v", summaryTest), datarace.Signatures()
+	} else if runtimeError := output.RuntimeErrors[fqTest]; runtimeError != nil {
+		return CategoryRuntimeError, fmt.Sprintf("%v: %v", runtimeError.SummaryPrefix(), summaryTest), nil
+	} else if crash := output.Crashes[fqTest]; crash != nil {
+		return CategoryCrash, fmt.Sprintf("%v: %v", crash.SummaryPrefix(), summaryTest), nil
+	} else if leak := output.Leaks[fqTest]; leak != nil {
+		return CategoryLeak, fmt.Sprintf("Test Goroutine Leak: %v", summaryTest), leak.Signatures()
+	} else if resourceFailure := output.ResourceFailures[fqTest]; resourceFailure != nil {
+		return CategoryResource, fmt.Sprintf("Test Resource Failure: %v", summaryTest), nil
+	} else if buildFailure := output.BuildFailures[fqTest]; buildFailure != nil {
+		return CategoryBuild, fmt.Sprintf("Build Failure: %v", summaryTest), nil
+	}
+
+	return CategoryUnknown, fmt.Sprintf("Test Unclassified Failure: %v", summaryTest), nil
+}
+
+// Returns an error if the test has no recorded failure. See `classify`.
 func GetSummaryForFailure(runFailure Failure, fqTest FQTest) (string, error) {
 	artifacts := runFailure.Output
-	if assertions := artifacts.Assertions[fqTest]; len(assertions) > 0 {
-		return fmt.Sprintf("Test Failure: %v", fqTest), nil
-	} else if timeout := artifacts.Timeouts[fqTest]; timeout != nil {
-		return fmt.Sprintf("Test Timeout: %v", fqTest), nil
-	} else if datarace := artifacts.Dataraces[fqTest]; datarace != nil {
-		return fmt.Sprintf("Test Datarace: %v", fqTest), nil
-	} else if runtimeError := artifacts.RuntimeErrors[fqTest]; runtimeError != nil {
-		return fmt.Sprintf("Test RuntimeError: %v", fqTest), nil
-	} else {
+	category, summary, _ := artifacts.classify(fqTest)
+	if category == CategoryUnknown && artifacts.UnknownFailures[fqTest] == nil {
 		if util.GDebug {
 			fmt.Println("Failure not found:", fqTest)
-			for failureFQTest := range artifacts.Dataraces {
-				fmt.Printf("DataraceKey: `%s`\n", failureFQTest)
-			}
-			for failureFQTest := range artifacts.RuntimeErrors {
-				fmt.Printf("RuntimeErrorKey: `%s`\n", failureFQTest)
-				if strings.HasPrefix(string(fqTest), string(failureFQTest)) {
-					fmt.Println("  Match")
-					return fmt.Sprintf("Test RuntimeError: %v", fqTest), nil
-				}
-			}
 		}
 
 		return "", fmt.Errorf("Unknown: `%s`", fqTest)
 	}
+
+	return summary, nil
+}
+
+// See `classify`.
+func GetSignaturesForFailure(runFailure Failure, fqTest FQTest) []string {
+	_, _, signatures := runFailure.Output.classify(fqTest)
+	return signatures
+}
+
+// Returns true if the existing ticket is for the same failure. That is, the summaries are equal or
+// a line of the ticket description is one of the failure's signatures. Signatures find the ticket
+// for the same datarace or goroutine leak regardless of which test (or package) hit it. Lines are
+// compared exactly: a signature for `pkg.Foo.func1` must not match one for `pkg.Foo.func12`.
+//
+// Tickets filed before summaries were normalized have the raw test name in their summary. E.g:
+// `Test Failure: pkg.TestServer/port_54321` is a duplicate of `Test Failure: pkg.TestServer/port_<n>`.
+func isDuplicateTicket(summary string, signatures []string, existingTicket jira.Issue) bool {
+	existingSummary := existingTicket.Fields.Summary
+	if summary == existingSummary || FQTest(summary) == TestNameNormalizer.Normalize(FQTest(existingSummary)) {
+		return true
+	}
+
+	if len(signatures) == 0 {
+		return false
+	}
+	for _, line := range strings.Split(existingTicket.Fields.Description, "\n") {
+		if slices.Contains(signatures, strings.TrimSpace(line)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// For deduping. Returns non-empty ticket string on match. E.g: `RSDK-5192`.
+func findExistingTicket(summary string, signatures []string, existingTickets []jira.Issue) string {
+	for _, existingTicket := range existingTickets {
+		if isDuplicateTicket(summary, signatures, existingTicket) {
+			return existingTicket.Key
+		}
+	}
+
+	return ""
 }
 
 func RunDedup(runFailure Failure, fqTest FQTest, openIssues []jira.Issue) error {
@@ -42,10 +103,11 @@ func RunDedup(runFailure Failure, fqTest FQTest, openIssues []jira.Issue) error
 	if err != nil {
 		return err
 	}
+	signatures := GetSignaturesForFailure(runFailure, fqTest)
 
 	fmt.Println("Summary:", summary)
 	for _, issue := range openIssues {
-		if summary == issue.Fields.Summary {
+		if isDuplicateTicket(summary, signatures, issue) {
 			fmt.Println("\tDedup match:", issue.Key)
 		}
 	}
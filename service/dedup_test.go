@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestIsDuplicateTicket(t *testing.T) {
+	ticket := func(summary, description string) jira.Issue {
+		return jira.Issue{Fields: &jira.IssueFields{Summary: summary, Description: description}}
+	}
+	const signature = "Goroutine leak signature: go.viam.com/rdk/robot/impl.(*localRobot).run.func1"
+	existing := ticket("Test Goroutine Leak: go.viam.com/rdk/robot/impl.TestRobotClose",
+		"{noformat}\nfound unexpected goroutines:\n  "+signature+"\r\n{noformat}")
+
+	if !isDuplicateTicket(existing.Fields.Summary, nil, existing) {
+		t.Fatalf("Expected equal summaries to be duplicates.")
+	}
+	if !isDuplicateTicket("Test Goroutine Leak: go.viam.com/rdk/robot/impl.TestRobotOpen", []string{signature}, existing) {
+		t.Fatalf("Expected the same signature to be a duplicate.")
+	}
+
+	// A signature that is a prefix of another is a different function.
+	for _, other := range []string{signature + "2", signature + ".1", "Goroutine leak signature: go.viam.com/rdk/robot/impl.(*localRobot).run"} {
+		if isDuplicateTicket("Test Goroutine Leak: go.viam.com/rdk/robot/impl.TestRobotOpen", []string{other}, existing) {
+			t.Fatalf("Expected `%v` to not match `%v`.", other, signature)
+		}
+	}
+
+	// Tickets filed with raw test names match the normalized summary.
+	raw := ticket("Test Failure: go.viam.com/rdk/robot/web.TestServer/port_54321", "")
+	if !isDuplicateTicket("Test Failure: go.viam.com/rdk/robot/web.TestServer/port_<n>", nil, raw) {
+		t.Fatalf("Expected a raw summary to be a duplicate of its normalized summary.")
+	}
+	if isDuplicateTicket("Test Failure: go.viam.com/rdk/robot/web.TestServer/host_<n>", nil, raw) {
+		t.Fatalf("Expected a different subtest to not be a duplicate.")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	const fqTest = FQTest(pkg + ".TestRobotClose/port_8080")
+	leak := &LeakFailure{Package: pkg, Goroutines: []*Goroutine{{
+		Frames: []StackFrame{{Func: "go.viam.com/rdk/robot/impl.(*localRobot).run"}},
+	}}}
+
+	for _, testCase := range []struct {
+		addFailure func(output *Output)
+		category   string
+		summary    string
+	}{
+		{func(output *Output) { output.Fuzz[fqTest] = &FuzzFailure{} }, CategoryFuzz, "Test Fuzz Failure"},
+		{func(output *Output) { output.Crashes[fqTest] = &CrashFailure{Kind: "panic"} }, CategoryCrash, "Test Panic"},
+		{func(output *Output) { output.Leaks[fqTest] = leak }, CategoryLeak, "Test Goroutine Leak"},
+		{func(output *Output) { output.ResourceFailures[fqTest] = &ResourceFailure{} }, CategoryResource, "Test Resource Failure"},
+		{func(output *Output) { output.BuildFailures[fqTest] = &BuildFailure{} }, CategoryBuild, "Build Failure"},
+		{func(output *Output) {}, CategoryUnknown, "Test Unclassified Failure"},
+	} {
+		output := NewTestSummary()
+		testCase.addFailure(output)
+
+		category, summary, signatures := output.classify(fqTest)
+		if expected := testCase.summary + ": " + pkg + ".TestRobotClose/port_<n>"; category != testCase.category ||
+			summary != expected {
+			t.Fatalf("Wrong classification. Expected: %v `%v` Actual: %v `%v`", testCase.category, expected, category, summary)
+		}
+		if (category == CategoryLeak) != (len(signatures) == 1) {
+			t.Fatalf("Wrong signatures for %v: %v", category, signatures)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Jira errors if a description is too long:
+//
+//	"errors":{
+//	  "description":"The entered text is too long. It exceeds the allowed limit of 32,767 characters."
+//	}
+const jiraDescriptionLimit = 32767
+
+// How an excerpt's size budget is split. Whatever is left over goes to the last lines.
+const (
+	excerptFocusShare = 0.5
+	excerptTailShare  = 0.3
+	excerptHeadShare  = 0.2
+)
+
+// The most bytes a single line can take up in an excerpt. Longer lines are clipped.
+const maxExcerptLineSize = 2000
+
+// E.g: "... [1234 lines elided] ..."
+func elidedMarker(numLines int) string {
+	return fmt.Sprintf("... [%d lines elided] ...", numLines)
+}
+
+// The worst case size of an elided marker, including its newline.
+var maxElidedMarkerSize = len(elidedMarker(1_000_000_000)) + 1
+
+func clipLine(line string) string {
+	if len(line) <= maxExcerptLineSize {
+		return line
+	}
+
+	return line[:maxExcerptLineSize] + "..."
+}
+
+// Returns the logs joined by newlines, in at most `maxSize` bytes. If the logs do not fit, the
+// lines around the `focus` lines, the last lines and the first lines are kept, in that order of
+// priority. Elided ranges are replaced with a marker that counts the elided lines. Returns nothing
+// if not even a marker fits.
+func excerpt(logs []string, maxSize int, focus []int) string {
+	if len(strings.Join(logs, "\n")) <= maxSize {
+		return strings.Join(logs, "\n")
+	}
+	if maxSize < maxElidedMarkerSize {
+		return ""
+	}
+
+	lines := make([]string, len(logs))
+	for idx, line := range logs {
+		lines[idx] = clipLine(line)
+	}
+
+	// Every kept range can be followed by a gap. Reserve room for the gaps' markers up front.
+	budget := maxSize - (len(focus)+2)*maxElidedMarkerSize
+	kept := make([]bool, len(lines))
+	used := 0
+	keep := func(idx, limit int) bool {
+		if idx < 0 || idx >= len(lines) {
+			return false
+		}
+		if kept[idx] {
+			return true
+		}
+
+		cost := len(lines[idx]) + 1
+		if used+cost > limit || used+cost > budget {
+			return false
+		}
+		kept[idx] = true
+		used += cost
+		return true
+	}
+
+	// Grow outward from each focus line. A side stops growing at its first line that does not fit,
+	// such that each focus line keeps a single range and the reserved markers suffice.
+	if len(focus) > 0 {
+		perFocus := int(float64(budget) * excerptFocusShare / float64(len(focus)))
+		for _, center := range focus {
+			limit := used + perFocus
+			if !keep(center, limit) {
+				continue
+			}
+			afterDone, beforeDone := false, false
+			for dist := 1; !afterDone || !beforeDone; dist++ {
+				afterDone = afterDone || !keep(center+dist, limit)
+				beforeDone = beforeDone || !keep(center-dist, limit)
+			}
+		}
+	}
+
+	tailLimit := used + int(float64(budget)*excerptTailShare)
+	for idx := len(lines) - 1; idx >= 0; idx-- {
+		if !keep(idx, tailLimit) {
+			break
+		}
+	}
+
+	headLimit := used + int(float64(budget)*excerptHeadShare)
+	for idx := 0; idx < len(lines); idx++ {
+		if !keep(idx, headLimit) {
+			break
+		}
+	}
+
+	// Give what remains of the budget to the end of the logs.
+	for idx := len(lines) - 1; idx >= 0; idx-- {
+		if !keep(idx, budget) {
+			break
+		}
+	}
+
+	ret := make([]string, 0)
+	numElided := 0
+	for idx, line := range lines {
+		if !kept[idx] {
+			numElided++
+			continue
+		}
+
+		if numElided > 0 {
+			ret = append(ret, elidedMarker(numElided))
+			numElided = 0
+		}
+		ret = append(ret, line)
+	}
+	if numElided > 0 {
+		ret = append(ret, elidedMarker(numElided))
+	}
+
+	return strings.Join(ret, "\n")
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser"
+)
+
+func TestExcerpt(t *testing.T) {
+	short := []string{"=== RUN   TestFoo", "--- FAIL: TestFoo (0.00s)"}
+	if actual := excerpt(short, 1000, parser.FocusLines(short)); actual != strings.Join(short, "\n") {
+		t.Fatalf("Expected logs that fit to be kept as is. Actual: %v", actual)
+	}
+
+	logs := make([]string, 0, 10000)
+	for idx := 0; idx < 10000; idx++ {
+		logs = append(logs, fmt.Sprintf("    logger.go:130: log line number %d", idx))
+	}
+	logs[9000] = "    ur5e_test.go:384: Expected: nil"
+	logs[9001] = "        Actual:   'timeout'"
+
+	focus := parser.FocusLines(logs)
+	if len(focus) != 1 || focus[0] != 9000 {
+		t.Fatalf("Wrong focus lines: %v", focus)
+	}
+
+	const maxSize = 5000
+	actual := excerpt(logs, maxSize, focus)
+	if len(actual) > maxSize {
+		t.Fatalf("Excerpt is too long: %d", len(actual))
+	}
+
+	lines := strings.Split(actual, "\n")
+	for _, expected := range []string{logs[0], logs[8990], logs[9000], logs[9001], logs[9010], logs[9999]} {
+		found := false
+		for _, line := range lines {
+			found = found || line == expected
+		}
+		if !found {
+			t.Fatalf("Expected the excerpt to keep `%v`:\n%v", expected, actual)
+		}
+	}
+
+	// The kept lines plus the elided lines add up to all the lines.
+	elidedRe := regexp.MustCompile(`^\.\.\. \[(\d+) lines elided\] \.\.\.$`)
+	total, numMarkers := 0, 0
+	for _, line := range lines {
+		if matches := elidedRe.FindStringSubmatch(line); len(matches) > 0 {
+			numElided, _ := strconv.Atoi(matches[1])
+			total += numElided
+			numMarkers++
+			continue
+		}
+		total++
+	}
+	if total != len(logs) || numMarkers < 2 {
+		t.Fatalf("Wrong elided ranges. Lines: %d Markers: %d", total, numMarkers)
+	}
+}
+
+func TestTicketDescriptionLimit(t *testing.T) {
+	const pkg = "go.viam.com/rdk/components/arm/universalrobots"
+	fqTest := FQTest(pkg + ".TestArmReconnection")
+	output := NewTestSummary()
+	logs := make([]string, 0, 5000)
+	for idx := 0; idx < 5000; idx++ {
+		logs = append(logs, fmt.Sprintf("    ur5e_test.go:100: some verbose log line %d", idx))
+	}
+	logs = append(logs, "    ur5e_test.go:384: Expected: nil", "        Actual:   'timeout'")
+	output.Assertions[fqTest] = []AssertionFailure{{Package: pkg, File: "ur5e_test.go", Line: 384, Expected: "nil", Actual: "'timeout'"}}
+	output.Logs[fqTest] = logs
+	output.TestFailures = []FQTest{fqTest}
+
+	tickets := CreateTicketObjectsFromFailure(Failure{Output: output})
+	if len(tickets) != 1 {
+		t.Fatalf("Expected one ticket. Actual: %d", len(tickets))
+	}
+	description := tickets[0].Issue.Fields.Description
+	if len(description) > jiraDescriptionLimit || !strings.Contains(description, "        Actual:   'timeout'") {
+		t.Fatalf("Description is too long or lost the assertion. Length: %d", len(description))
+	}
+}
+
+func TestTicketDescriptionLimitOversizedAssertion(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	fqTest := FQTest(pkg + ".TestConfig")
+	actualLines := make([]string, 0, 10000)
+	for idx := 0; idx < 10000; idx++ {
+		actualLines = append(actualLines, fmt.Sprintf(`  "component_%d": {"type": "arm", "model": "fake"},`, idx))
+	}
+	output := NewTestSummary()
+	output.Assertions[fqTest] = []AssertionFailure{{
+		Package: pkg, File: "config_test.go", Line: 12, Expected: "nil", Actual: strings.Join(actualLines, "\n"),
+	}}
+	output.Logs[fqTest] = []string{"=== RUN   TestConfig", "    config_test.go:12: Expected: nil", "--- FAIL: TestConfig (0.01s)"}
+	output.TestFailures = []FQTest{fqTest}
+
+	tickets := CreateTicketObjectsFromFailure(Failure{Output: output})
+	if len(tickets) != 1 {
+		t.Fatalf("Expected one ticket. Actual: %d", len(tickets))
+	}
+	description := tickets[0].Issue.Fields.Description
+	if len(description) > jiraDescriptionLimit || !strings.Contains(description, "Expected: nil") ||
+		!strings.Contains(description, "lines elided") {
+		t.Fatalf("Expected the assertion to be cut down. Length: %d", len(description))
+	}
+
+	if actual := excerpt(actualLines, 10, nil); actual != "" {
+		t.Fatalf("Expected nothing to fit. Actual: %v", actual)
+	}
+}
+
+// Long lines next to short ones must not leave gaps that the reserved markers do not cover.
+func TestExcerptMixedLineSizes(t *testing.T) {
+	logs := make([]string, 0, 3000)
+	for idx := 0; idx < 1000; idx++ {
+		logs = append(logs, fmt.Sprintf("short %d", idx))
+	}
+	logs = append(logs, "    foo_test.go:12: Expected: nil")
+	for idx := 0; idx < 1000; idx++ {
+		logs = append(logs, strings.Repeat("x", 1500), "y")
+	}
+
+	focus := parser.FocusLines(logs)
+	for _, maxSize := range []int{5000, 20000, jiraDescriptionLimit} {
+		if actual := excerpt(logs, maxSize, focus); len(actual) > maxSize {
+			t.Fatalf("Excerpt is too long. Limit: %d Actual: %d", maxSize, len(actual))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestRerunFlakes(t *testing.T) {
+	const pkg = "go.viam.com/rdk/services/motion/builtin"
+	output := func(test, line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+	fail := func(test string) []TestLogLine {
+		return []TestLogLine{
+			{Action: "run", Package: pkg, Test: test},
+			output(test, "=== RUN   "+test),
+			output(test, "    builtin_test.go:42: Expected: 1"),
+			output(test, "        Actual:   2"),
+			output(test, "--- FAIL: "+test+" (0.10s)"),
+			{Action: "fail", Package: pkg, Test: test},
+		}
+	}
+	pass := func(test string) []TestLogLine {
+		return []TestLogLine{
+			{Action: "run", Package: pkg, Test: test},
+			output(test, "--- PASS: "+test+" (0.10s)"),
+			{Action: "pass", Package: pkg, Test: test},
+		}
+	}
+
+	// `gotestsum --rerun-fails=2` reruns the failed tests until they pass.
+	var stream []TestLogLine
+	stream = append(stream, fail("TestMoveOnMap")...)
+	stream = append(stream, fail("TestMoveOnGlobe")...)
+	stream = append(stream, TestLogLine{Action: "fail", Package: pkg})
+	stream = append(stream, fail("TestMoveOnMap")...)
+	stream = append(stream, fail("TestMoveOnGlobe")...)
+	stream = append(stream, TestLogLine{Action: "fail", Package: pkg})
+	stream = append(stream, pass("TestMoveOnMap")...)
+	stream = append(stream, fail("TestMoveOnGlobe")...)
+	stream = append(stream, TestLogLine{Action: "fail", Package: pkg})
+
+	logs, err := parseFailures(context.Background(), parsertest.LogReader(stream...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flakeTest := FQTest(pkg + ".TestMoveOnMap")
+	hardTest := FQTest(pkg + ".TestMoveOnGlobe")
+	if len(logs.TestFailures) != 1 || logs.TestFailures[0] != hardTest {
+		t.Fatalf("Expected only the hard failure. Actual: %v", logs.TestFailures)
+	}
+	flake := logs.Flakes[flakeTest]
+	if flake == nil || flake.PassedOnRerun() != 2 || flake.ToPrettyString() != "flaked, passed on rerun 2" {
+		t.Fatalf("Wrong flake: %+v", flake)
+	}
+	if _, exists := logs.Flakes[hardTest]; exists {
+		t.Fatalf("A test that never passed is not a flake.")
+	}
+	if logs.IsSuccess() {
+		t.Fatalf("Expected the hard failure to fail the output.")
+	}
+	if toTicket := logs.FailuresToTicket(); len(toTicket) != 2 {
+		t.Fatalf("Expected tickets for the flake and the hard failure. Actual: %v", toTicket)
+	}
+}
+
+func TestRerunFlakesOnly(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	output := func(line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: "TestReconfigure", Output: line + "\n"}
+	}
+
+	logs, err := parseFailures(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "run", Package: pkg, Test: "TestReconfigure"},
+		output("    impl_test.go:7: Expected: nil"),
+		output("        Actual:   'context canceled'"),
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestReconfigure"},
+		TestLogLine{Action: "fail", Package: pkg},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestReconfigure"},
+		TestLogLine{Action: "pass", Package: pkg, Test: "TestReconfigure"},
+		TestLogLine{Action: "pass", Package: pkg},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The job succeeded, but the flake still gets a ticket.
+	if !logs.IsSuccess() || len(logs.PackageFailures) != 0 {
+		t.Fatalf("Expected a flake to not fail the output. Failures: %v Package failures: %v",
+			logs.TestFailures, logs.PackageFailures)
+	}
+
+	tickets := CreateTicketObjectsFromFailure(Failure{Output: logs})
+	if len(tickets) != 1 {
+		t.Fatalf("Expected a ticket for the flake. Actual: %d", len(tickets))
+	}
+	if summary := tickets[0].Issue.Fields.Summary; summary != "Test Failure: "+pkg+".TestReconfigure" {
+		t.Fatalf("Wrong summary: %v", summary)
+	}
+	if description := tickets[0].Issue.Fields.Description; !strings.Contains(description, "Flaked, passed on rerun 1") {
+		t.Fatalf("Expected the description to mention the rerun. Actual:\n%v", description)
+	}
+}
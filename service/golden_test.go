@@ -0,0 +1,183 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// E.g: `go test ./service -run TestGolden -update`.
+var updateGolden = flag.Bool("update", false, "Rewrite the golden files in `testdata/golden`.")
+
+// The test log archives in `testdata`, by the name of their golden files.
+var goldenFixtures = map[string]string{
+	"failure_context":             "failure_context_test_logs.json.zip",
+	"timeout_context":             "timeout_context_test_logs.json.zip",
+	"datarace_context":            "datarace_context_test_logs.json.zip",
+	"failure_followed_by_timeout": "failure_followed_by_timeout_test_logs.zip",
+	"failure_no_failures":         "failure_no_failures.zip",
+}
+
+// Fields of the parse result that are replaced with their size in the golden files. The logs
+// are checked as part of the tickets.
+var goldenCountedFields = []string{"LogLines", "Goroutines"}
+
+// Returns the parse result as checked into the golden files. E.g:
+//
+//	{
+//	  "Assertions": {"go.viam.com/rdk/...TestFoo": [{"File": "foo_test.go", "Line": 12, ...}]},
+//	  "TestStatuses": {"fail": 1, "pass": 1287, "skip": 12},
+//	  ...
+//	}
+func goldenResult(output *Output) ([]byte, error) {
+	encoded, err := json.Marshal(output.Result)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+
+	// The full logs and lifecycle of every test would make the golden files unreviewable.
+	for _, field := range []string{"Logs", "LogEntries", "Tests", "Packages", "UntaggedOutput"} {
+		delete(fields, field)
+	}
+	testStatuses := make(map[string]int)
+	for _, record := range output.Tests {
+		testStatuses[record.Status]++
+	}
+	fields["TestStatuses"] = testStatuses
+	fields["NumPackages"] = len(output.Packages)
+	fields["NumUntaggedOutput"] = len(output.UntaggedOutput)
+	countGoldenFields(fields)
+
+	return json.MarshalIndent(fields, "", "  ")
+}
+
+// Replaces the `goldenCountedFields` with their size, at any depth. E.g: `"LogLines": [...]`
+// becomes `"NumLogLines": 42`.
+func countGoldenFields(value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		for _, field := range goldenCountedFields {
+			if list, isList := value[field].([]any); isList {
+				delete(value, field)
+				value["Num"+field] = len(list)
+			}
+		}
+		for _, nested := range value {
+			countGoldenFields(nested)
+		}
+	case []any:
+		for _, nested := range value {
+			countGoldenFields(nested)
+		}
+	}
+}
+
+// Returns the tickets that would be filed, as plain text such that changes to the descriptions
+// diff line by line.
+func goldenTickets(tickets []TicketPlusLogs) []byte {
+	var ret bytes.Buffer
+	if len(tickets) == 0 {
+		ret.WriteString("No tickets.\n")
+	}
+	for _, ticket := range tickets {
+		fields := ticket.Issue.Fields
+		fmt.Fprintf(&ret, "=== Summary: %v\n", fields.Summary)
+		fmt.Fprintf(&ret, "Project: %v\n", fields.Project.Key)
+		fmt.Fprintf(&ret, "Labels: %v\n", strings.Join(fields.Labels, ", "))
+		fmt.Fprintf(&ret, "Logs: %d lines\n", len(ticket.Logs))
+		for _, attachment := range ticket.Attachments {
+			fmt.Fprintf(&ret, "Attachment: %v (%d lines)\n", attachment.Name, len(attachment.Lines))
+		}
+		for _, signature := range ticket.Signatures {
+			fmt.Fprintf(&ret, "Signature: %v\n", signature)
+		}
+		fmt.Fprintf(&ret, "Description:\n%v\n", fields.Description)
+	}
+
+	return ret.Bytes()
+}
+
+// Compares `actual` with the golden file, or rewrites the golden file with `-update`.
+func checkGolden(t *testing.T, path string, actual []byte) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Missing golden file. Run with `-update` to create it. Err: %v", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		expectedLines, actualLines := strings.Split(string(expected), "\n"), strings.Split(string(actual), "\n")
+		for idx := 0; idx < len(expectedLines) && idx < len(actualLines); idx++ {
+			if expectedLines[idx] != actualLines[idx] {
+				t.Fatalf("%v differs at line %d. Run with `-update` to accept the change.\nExpected: %v\nActual:   %v",
+					path, idx+1, expectedLines[idx], actualLines[idx])
+			}
+		}
+		t.Fatalf("%v differs in length. Run with `-update` to accept the change. Expected: %d lines Actual: %d lines",
+			path, len(expectedLines), len(actualLines))
+	}
+}
+
+func TestGolden(t *testing.T) {
+	names := make([]string, 0, len(goldenFixtures))
+	for name := range goldenFixtures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			archive, err := zip.OpenReader(filepath.Join("testdata", goldenFixtures[name]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer archive.Close()
+
+			output, err := parseFailures(context.Background(), zipFileToReader(archive))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := goldenResult(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join("testdata", "golden", name+".result.json"), result)
+
+			tickets := CreateTicketObjectsFromFailure(Failure{
+				Variant:    "amd64",
+				GithubLink: "https://github.com/viamrobotics/rdk/actions/runs/5859328480/job/15885094207",
+				GitHash:    "abc123",
+				Output:     output,
+				WorkflowRun: &github.WorkflowRun{
+					Repository: &github.Repository{Name: github.String("rdk")},
+				},
+			})
+			checkGolden(t, filepath.Join("testdata", "golden", name+".tickets.txt"), goldenTickets(tickets))
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/andygrunwald/go-jira"
 	"github.com/trivago/tgo/tcontainer"
+	"github.com/viamrobotics/bfserver/parser"
 	"github.com/viamrobotics/bfserver/util"
 )
 
@@ -28,9 +29,11 @@ func truncate(logs []string, maxSize int) string {
 	return strings.Join(logs, "\n")
 }
 
+// Returns the run and job id of a github link. The job id is 0 for a link to the run, e.g: for
+// failures in JUnit artifacts.
 func getRunJobFromURL(githubRunUrl string) (int64, int64) {
 	// Example url: https://github.com/viamrobotics/rdk/actions/runs/5859328480/job/15885094207
-	runJobRe := regexp.MustCompile(`/actions/runs/(\d+)/job/(\d+)`)
+	runJobRe := regexp.MustCompile(`/actions/runs/(\d+)(?:/job/(\d+))?`)
 	matches := runJobRe.FindStringSubmatch(githubRunUrl)
 	if len(matches) == 0 {
 		fmt.Println("No matches parsing the run id from the link:", githubRunUrl)
@@ -45,6 +48,9 @@ func getRunJobFromURL(githubRunUrl string) (int64, int64) {
 	}
 
 	matchIdx++
+	if matches[matchIdx] == "" {
+		return runId, 0
+	}
 	jobId, err := strconv.ParseInt(matches[matchIdx], 10, 64)
 	if err != nil {
 		fmt.Println("Error parsing the job id from the link:", githubRunUrl)
@@ -66,20 +72,9 @@ func PushTickets(newTickets []TicketPlusLogs, existingTickets []jira.Issue, gith
 	}
 	jiraClient, _ := jira.NewClient(tp.Client(), "https://viam.atlassian.net/")
 
-	// For deduping. Returns non-empty ticket string on match. E.g: `RSDK-5192`.
-	exists := func(failure *jira.Issue) string {
-		for _, existingTicket := range existingTickets {
-			if failure.Fields.Summary == existingTicket.Fields.Summary {
-				return existingTicket.Key
-			}
-		}
-
-		return ""
-	}
-
 	for _, ticketAndLogs := range newTickets {
-		ticket, logs := ticketAndLogs.Issue, ticketAndLogs.Logs
-		if name := exists(ticket); name != "" {
+		ticket := ticketAndLogs.Issue
+		if name := findExistingTicket(ticket.Fields.Summary, ticketAndLogs.Signatures, existingTickets); name != "" {
 			ticket.Key = name
 			fmt.Println("Failure exists.\n\tTicket:", name, "\n\tSummary:", ticket.Fields.Summary)
 			jiraClient.Issue.AddRemoteLink(name, &jira.RemoteLink{
@@ -89,15 +84,7 @@ func PushTickets(newTickets []TicketPlusLogs, existingTickets []jira.Issue, gith
 				}})
 
 			fmt.Println("Posting attachment:", githubJobUrl)
-			runId, jobId := getRunJobFromURL(githubJobUrl)
-			_, resp, err := jiraClient.Issue.PostAttachment(ticket.Key, strings.NewReader(strings.Join(logs, "\n")), fmt.Sprintf("logs.%d.%d", runId, jobId))
-			if err != nil {
-				fmt.Println("Header:", resp.Header)
-				msg, err2 := io.ReadAll(resp.Body)
-				fmt.Println("Msg:", string(msg))
-				fmt.Println("Reading err?", err2)
-				panic(err)
-			}
+			postAttachments(jiraClient, ticket.Key, ticketAndLogs, githubJobUrl)
 			continue
 		}
 
@@ -110,9 +97,31 @@ func PushTickets(newTickets []TicketPlusLogs, existingTickets []jira.Issue, gith
 			panic(err)
 		}
 		ticket.Key = filed.Key
+		// Normalized summaries can be shared by failures in the same run, e.g: `TestFoo/case_1` and
+		// `TestFoo/case_2`. Those are linked to the ticket filed for the first one.
+		existingTickets = append(existingTickets, *ticket)
+
+		postAttachments(jiraClient, filed.Key, ticketAndLogs, githubJobUrl)
+	}
+
+	return nil
+}
 
-		runId, jobId := getRunJobFromURL(githubRunUrl)
-		_, resp, err = jiraClient.Issue.PostAttachment(filed.Key, strings.NewReader(strings.Join(logs, "\n")), fmt.Sprintf("logs.%d.%d", runId, jobId))
+// Attaches the test logs and any additional attachments to the ticket. Attachment filenames are
+// suffixed with the github run and job id, if known. E.g: `logs.5859328480.15885094207`.
+func postAttachments(jiraClient *jira.Client, ticketKey string, ticketAndLogs TicketPlusLogs, githubJobUrl string) {
+	runId, jobId := getRunJobFromURL(githubJobUrl)
+	attachments := append([]Attachment{{Name: "logs", Lines: ticketAndLogs.Logs}}, ticketAndLogs.Attachments...)
+	for _, attachment := range attachments {
+		filename := fmt.Sprintf("%s.%d", attachment.Name, runId)
+		if jobId != 0 {
+			filename = fmt.Sprintf("%s.%d", filename, jobId)
+		}
+		if attachment.KeepName {
+			filename = attachment.Name
+		}
+		_, resp, err := jiraClient.Issue.PostAttachment(ticketKey,
+			strings.NewReader(strings.Join(attachment.Lines, "\n")), filename)
 		if err != nil {
 			fmt.Println("Header:", resp.Header)
 			msg, err2 := io.ReadAll(resp.Body)
@@ -121,20 +130,42 @@ func PushTickets(newTickets []TicketPlusLogs, existingTickets []jira.Issue, gith
 			panic(err)
 		}
 	}
-
-	return nil
 }
 
+// The maximum size of a goroutine summary in a ticket description. The remainder of the
+// description's size limit is left for test logs.
+const maxGoroutineSummarySize = 15000
+
+// The maximum size of the structured log summary in a ticket description.
+const maxLogSummarySize = 5000
+
+// The maximum size of the assertion (or other failure message) in a ticket description. E.g: an
+// `Actual` value can be an entire serialized config.
+const maxAssertionMessageSize = 20000
+
 type TicketPlusLogs struct {
 	Issue *jira.Issue
 	Logs  []string
+	// Files attached to the ticket in addition to the logs. E.g: a raw goroutine dump.
+	Attachments []Attachment
+	// Lines in the ticket description that identify the failure independent of the test that hit
+	// it. Used for deduping. See `classify`.
+	Signatures []string
+}
+
+type Attachment struct {
+	// The filename prefix. E.g: `goroutines`.
+	Name  string
+	Lines []string
+	// Post the attachment as `Name`, without the github run and job id suffix.
+	KeepName bool
 }
 
 func CreateTicketObjectsFromFailure(runFailure Failure) []TicketPlusLogs {
 	ret := make([]TicketPlusLogs, 0)
 
 	artifacts := runFailure.Output
-	for _, fqTest := range artifacts.TestFailures {
+	for _, fqTest := range artifacts.FailuresToTicket() {
 		fmt.Println("Test:", fqTest, "NumLogs:", len(artifacts.Logs[fqTest]))
 		if len(artifacts.Logs[fqTest]) == 0 {
 			if util.GDebug {
@@ -143,28 +174,98 @@ func CreateTicketObjectsFromFailure(runFailure Failure) []TicketPlusLogs {
 			continue
 		}
 
-		var summary string
 		var assertionMsg string
 		var assertionCodeLink string
+		var attachments []Attachment
+		// Every ticket keeps the `flaky_test` label such that it is found when deduping.
+		labels := []string{"flaky_test"}
 
-		// Consolidate with `GetSummaryForFailure`?
-		if assertions := artifacts.Assertions[fqTest]; len(assertions) > 0 {
-			summary = fmt.Sprintf("Test Failure: %v", fqTest)
+		category, summary, signatures := artifacts.classify(fqTest)
+		switch category {
+		case CategoryFuzz:
+			fuzz := artifacts.Fuzz[fqTest]
+			assertionMsg = fuzz.ToPrettyString("")
+			// A fuzz target's own assertion explains why the input failed.
+			if assertions := artifacts.Assertions[fqTest]; len(assertions) > 0 {
+				assertionMsg = fmt.Sprintf("%v\n\n%v", assertions[0].ToPrettyString(""), assertionMsg)
+			}
+			if len(fuzz.Corpus) > 0 {
+				// Named after the corpus file such that it can be committed to the package's
+				// `testdata/fuzz` directory as is.
+				attachments = append(attachments, Attachment{Name: fuzz.Hash, Lines: fuzz.Corpus, KeepName: true})
+			}
+		case CategoryJS:
+			jsFailure := artifacts.JSFailures[fqTest]
+			assertionMsg = jsFailure.ToPrettyString("")
+			assertionCodeLink = GetJSCodeLinkWithText(jsFailure, " (Code Link)", runFailure)
+		case CategoryAssertion:
+			assertions := artifacts.Assertions[fqTest]
 			assertionMsg = assertions[0].ToPrettyString("")
-			assertionCodeLink = assertions[0].GetAssertionCodeLinkWithText(
-				" (Code Link)", runFailure)
-		} else if timeout := artifacts.Timeouts[fqTest]; timeout != nil {
-			summary = fmt.Sprintf("Test Timeout: %v", fqTest)
-			assertionMsg = timeout.LogLines[0]
-		} else if datarace := artifacts.Dataraces[fqTest]; datarace != nil {
-			summary = fmt.Sprintf("Test Datarace: %v", fqTest)
+			assertionCodeLink = GetAssertionCodeLinkWithText(
+				assertions[0], " (Code Link)", runFailure)
+		case CategoryTimeout:
+			timeout := artifacts.Timeouts[fqTest]
+			// The full goroutine dump is often larger than jira allows for a description. Show the
+			// goroutines grouped by stack and attach the raw dump.
+			assertionMsg = fmt.Sprintf("%v\n\n%v", timeout.ToPrettyString(),
+				truncate(strings.Split(timeout.GoroutineSummary(""), "\n"), maxGoroutineSummarySize))
+			attachments = append(attachments, Attachment{Name: "goroutines", Lines: timeout.LogLines})
+		case CategoryDatarace:
+			datarace := artifacts.Dataraces[fqTest]
 			assertionMsg = datarace.LogLines[0]
-		} else if runtimeError := artifacts.RuntimeErrors[fqTest]; runtimeError != nil {
-			summary = fmt.Sprintf("Test RuntimeError: %v", fqTest)
-			assertionMsg = runtimeError.LogLines[0]
-		} else {
-			panic("Unknown")
+			if len(datarace.Races) > 0 {
+				assertionMsg = datarace.ToPrettyString("")
+			}
+			attachments = append(attachments, Attachment{Name: "datarace", Lines: datarace.LogLines})
+		case CategoryRuntimeError:
+			runtimeError := artifacts.RuntimeErrors[fqTest]
+			assertionMsg = runtimeError.ToPrettyString("")
+			attachments = append(attachments, Attachment{Name: "crash", Lines: runtimeError.LogLines})
+		case CategoryCrash:
+			crash := artifacts.Crashes[fqTest]
+			assertionMsg = crash.ToPrettyString("")
+			attachments = append(attachments, Attachment{Name: "crash", Lines: crash.LogLines})
+		case CategoryLeak:
+			leak := artifacts.Leaks[fqTest]
+			assertionMsg = leak.ToPrettyString("")
+			attachments = append(attachments, Attachment{Name: "leak", Lines: leak.LogLines})
+		case CategoryResource:
+			assertionMsg = artifacts.ResourceFailures[fqTest].ToPrettyString("")
+			// A killed test binary is a CI infrastructure problem rather than a test bug.
+			labels = append(labels, "ci_infra")
+		case CategoryBuild:
+			assertionMsg = artifacts.BuildFailures[fqTest].ToPrettyString("")
+			// Build failures are not flaky tests.
+			labels = append(labels, "build_failure")
+		default:
+			// `parseFailures` records all unclassified test failures as unknown failures. Fall back to
+			// building one from the logs rather than losing the failure.
+			unknownFailure := artifacts.UnknownFailures[fqTest]
+			if unknownFailure == nil {
+				unknownFailure = parser.NewUnknownFailure(artifacts.Logs[fqTest])
+			}
+			assertionMsg = strings.Join(unknownFailure.LogLines, "\n")
+		}
+
+		assertionLines := strings.Split(assertionMsg, "\n")
+		assertionMsg = excerpt(assertionLines, maxAssertionMessageSize, parser.FocusLines(assertionLines))
+
+		// E.g: the parent tests of a subtest.
+		var testContext string
+		if ancestry := artifacts.AncestryString(fqTest); ancestry != "" {
+			testContext = fmt.Sprintf("Subtest of: %v\n\n", ancestry)
 		}
+		if flake := artifacts.Flakes[fqTest]; flake != nil {
+			testContext = fmt.Sprintf("Flaked, passed on rerun %d\n\n%v", flake.PassedOnRerun(), testContext)
+		}
+
+		// The warnings and errors of the structured logs, next to the raw logs they are taken from.
+		var logSummary string
+		if summaryLines := artifacts.LogSummary(fqTest, ""); len(summaryLines) > 0 {
+			logSummary = fmt.Sprintf("Log Summary:\n\n{noformat}\n%v\n{noformat}\n\n",
+				truncate(summaryLines, maxLogSummarySize))
+		}
+		descriptionLogs := artifacts.Logs[fqTest]
 
 		var project string
 		switch runFailure.WorkflowRun.GetRepository().GetName() {
@@ -176,6 +277,17 @@ func CreateTicketObjectsFromFailure(runFailure Failure) []TicketPlusLogs {
 			project = "APP"
 		}
 
+		descriptionFormat := "[Github Run|%v]\n\n" +
+			"%s" +
+			"Assertion%s:\n\n{noformat}\n%v\n{noformat}\n\n" +
+			"%s" +
+			"Logs:\n\n{noformat}\n%v\n{noformat}\n\n"
+		withoutLogs := fmt.Sprintf(descriptionFormat,
+			runFailure.GithubLink, testContext, assertionCodeLink, assertionMsg, logSummary, "")
+		description := fmt.Sprintf(descriptionFormat,
+			runFailure.GithubLink, testContext, assertionCodeLink, assertionMsg, logSummary,
+			excerpt(descriptionLogs, max(jiraDescriptionLimit-len(withoutLogs), 0), parser.FocusLines(descriptionLogs)))
+
 		ticket := &jira.Issue{
 			Fields: &jira.IssueFields{
 				Project: jira.Project{
@@ -184,19 +296,9 @@ func CreateTicketObjectsFromFailure(runFailure Failure) []TicketPlusLogs {
 				Type: jira.IssueType{
 					Name: "Bug",
 				},
-				Summary: summary,
-				Description: fmt.Sprintf("[Github Run|%v]\n\n"+
-					"Assertion%s:\n\n{noformat}\n%v\n{noformat}\n\n"+
-					"Logs:\n\n{noformat}\n%v\n{noformat}\n\n",
-					runFailure.GithubLink,
-					assertionCodeLink,
-					assertionMsg,
-					// Jira errors if the description is too long:
-					// "errors":{
-					//   "description":"The entered text is too long. It exceeds the allowed limit of 32,767 characters."
-					// }
-					truncate(artifacts.Logs[fqTest], 30000)),
-				Labels: []string{"flaky_test"},
+				Summary:     summary,
+				Description: description,
+				Labels:      labels,
 				Unknowns: tcontainer.MarshalMap(map[string]interface{}{
 					// Team
 					"customfield_10074": []map[string]string{
@@ -206,7 +308,8 @@ func CreateTicketObjectsFromFailure(runFailure Failure) []TicketPlusLogs {
 			},
 		}
 
-		ret = append(ret, TicketPlusLogs{ticket, artifacts.Logs[fqTest]})
+		ret = append(ret, TicketPlusLogs{
+			ticket, artifacts.Logs[fqTest], attachments, signatures})
 	}
 
 	return ret
@@ -6,18 +6,25 @@ import (
 	"testing"
 
 	"github.com/google/go-github/v61/github"
+	"github.com/viamrobotics/bfserver/util"
 )
 
 func TestJira(t *testing.T) {
-	GetOpenBFs()
+	if jiraUsername == "" || jiraToken == "" {
+		t.Skip("No jira_username or jira_api_token")
+	}
+	GetOpenFlakeyFailureTickets(jiraUsername, jiraToken)
 }
 
 func TestCreateNewTicketFromFailure(t *testing.T) {
-	GDebug = true
+	if githubToken == "" {
+		t.Skip("No github_token")
+	}
+	util.GDebug = true
 	ctx := context.Background()
 	client := github.NewTokenClient(ctx, githubToken)
 
-	failures, err := GithubRunToFailedTests(ctx, client, 5977123166, 16216407061)
+	failures, err := GithubRunToFailedTests(ctx, client, "rdk", 5977123166, 16216407061, false)
 	if err != nil {
 		panic(err)
 	}
@@ -26,5 +33,18 @@ func TestCreateNewTicketFromFailure(t *testing.T) {
 		panic(fmt.Sprintf("Wrong number of failures: %v", len(failures)))
 	}
 
-	CreateNewTicketsFromFailure(failures[0], "", false)
+	CreateTicketObjectsFromFailure(failures[0])
+}
+
+func TestGetRunJobFromURL(t *testing.T) {
+	runId, jobId := getRunJobFromURL("https://github.com/viamrobotics/rdk/actions/runs/5859328480/job/15885094207")
+	if runId != 5859328480 || jobId != 15885094207 {
+		t.Fatalf("Wrong ids: %v %v", runId, jobId)
+	}
+
+	// JUnit failures link to the run.
+	runId, jobId = getRunJobFromURL("https://github.com/viamrobotics/rdk/actions/runs/5859328480")
+	if runId != 5859328480 || jobId != 0 {
+		t.Fatalf("Wrong ids: %v %v", runId, jobId)
+	}
 }
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Rewrites dynamic tokens in test names. Table-driven subtests can have ports, timestamps, UUIDs
+// or random seeds in their names, e.g: `TestServer/port_54321`. Without normalizing, the same
+// flake gets a new ticket every run.
+type NameRule struct {
+	Name string
+	Re   *regexp.Regexp
+	// The placeholder a matching token is replaced with. E.g: `<n>`.
+	Replacement string
+}
+
+// Delimits a token: the start or end of the name, or a character that is not a letter or digit.
+// The delimiters are captured such that the replacement can keep them.
+const (
+	tokenStart = `(^|[^0-9A-Za-z])`
+	tokenEnd   = `($|[^0-9A-Za-z])`
+)
+
+// Returns a rule that only rewrites whole tokens. E.g: the `2` in `2D_plan_test` is kept.
+func tokenRule(name, re, placeholder string) NameRule {
+	return NameRule{
+		Name:        name,
+		Re:          regexp.MustCompile(tokenStart + `(?:` + re + `)` + tokenEnd),
+		Replacement: "${1}" + placeholder + "${2}",
+	}
+}
+
+// The built-in rules, applied in order.
+var DefaultNameRules = []NameRule{
+	tokenRule("uuid", `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, "<uuid>"),
+	// E.g: `2023-09-08T22:29:00.123Z`, `2023-09-08_22:29:00` or `2023-09-08`.
+	tokenRule("timestamp", `\d{4}-\d{2}-\d{2}(?:[T_]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?`, "<time>"),
+	tokenRule("number", `\d+`, "<n>"),
+	// E.g: `0x1f` or `deadbeef0123`. Shorter hex strings are too easily confused with words.
+	tokenRule("hex", `0x[0-9a-fA-F]+|[0-9a-fA-F]{12,}`, "<hex>"),
+}
+
+type NameNormalizer struct {
+	rules []NameRule
+}
+
+func NewNameNormalizer(rules ...NameRule) *NameNormalizer {
+	return &NameNormalizer{rules: append([]NameRule(nil), rules...)}
+}
+
+// The normalizer used for ticket summaries. Replace it to apply custom rules. Custom rules should
+// come before the `DefaultNameRules`, else the built-in rules may rewrite their tokens first.
+var TestNameNormalizer = NewNameNormalizer(DefaultNameRules...)
+
+// Replaces the rule's matches. Adjacent tokens share a delimiter, e.g: `1_2`, and a match consumes
+// it, so the rule is repeated until the segment stops changing. A rule's replacement must not match
+// the rule itself.
+func (rule NameRule) apply(segment string) string {
+	for range segment {
+		replaced := rule.Re.ReplaceAllString(segment, rule.Replacement)
+		if replaced == segment {
+			break
+		}
+		segment = replaced
+	}
+
+	return segment
+}
+
+// E.g: ".TestMoveOnGlobe" in "go.viam.com/rdk/services/motion/builtin.TestMoveOnGlobe/sub".
+var testNameStartRe *regexp.Regexp = regexp.MustCompile(`\.(?:Test|Fuzz|Benchmark|Example)[^./]*`)
+
+// Rewrites the dynamic tokens in the subtest names of a test. Package and top-level test names
+// are left alone. E.g: `pkg.TestServer/port_54321` returns `pkg.TestServer/port_<n>`.
+func (normalizer *NameNormalizer) Normalize(fqTest FQTest) FQTest {
+	loc := testNameStartRe.FindStringIndex(string(fqTest))
+	if loc == nil || loc[1] == len(fqTest) {
+		return fqTest
+	}
+
+	segments := strings.Split(string(fqTest[loc[1]+1:]), "/")
+	for idx := range segments {
+		for _, rule := range normalizer.rules {
+			segments[idx] = rule.apply(segments[idx])
+		}
+	}
+
+	return fqTest[:loc[1]+1] + FQTest(strings.Join(segments, "/"))
+}
+
+// Parses rules, one per line, of the form `<name> <regexp> <replacement>`. Blank lines and lines
+// starting with `#` are ignored. E.g:
+//
+//	seed seed_\d+ seed_<seed>
+func ParseNameRules(reader io.Reader) ([]NameRule, error) {
+	ret := make([]NameRule, 0)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("Bad name rule line: `%v`", line)
+		}
+
+		re, err := regexp.Compile(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("Bad name rule regexp: `%v` Err: %w", fields[1], err)
+		}
+		ret = append(ret, NameRule{Name: fields[0], Re: re, Replacement: fields[2]})
+	}
+
+	return ret, scanner.Err()
+}
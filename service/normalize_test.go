@@ -0,0 +1,46 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTestName(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/web"
+	for input, expected := range map[string]string{
+		// Package and top-level test names are left alone.
+		pkg:                        pkg,
+		pkg + ".TestWebStart2":     pkg + ".TestWebStart2",
+		pkg + ".TestWeb/port_8080": pkg + ".TestWeb/port_<n>",
+		pkg + ".TestWeb/2D_plan_test/seed=1694212140":                     pkg + ".TestWeb/2D_plan_test/seed=<n>",
+		pkg + ".TestWeb/2D_arm/arm2D/v1.2":                                pkg + ".TestWeb/2D_arm/arm2D/v1.<n>",
+		pkg + ".TestWeb/case_1_2":                                         pkg + ".TestWeb/case_<n>_<n>",
+		pkg + ".TestWeb/robot_5f0c1a2b-3c4d-4e5f-8a9b-0c1d2e3f4a5b":       pkg + ".TestWeb/robot_<uuid>",
+		pkg + ".TestWeb/at_2023-09-08T22:29:00.123Z":                      pkg + ".TestWeb/at_<time>",
+		pkg + ".TestWeb/addr_0xc000123abc/hash_deadbeefcafe0123/ur5e_arm": pkg + ".TestWeb/addr_<hex>/hash_<hex>/ur5e_arm",
+	} {
+		if actual := TestNameNormalizer.Normalize(FQTest(input)); actual != FQTest(expected) {
+			t.Fatalf("Wrong normalization for `%v`. Expected: `%v` Actual: `%v`", input, expected, actual)
+		}
+	}
+
+	// Rules only match whole tokens, also when used on their own.
+	for _, rule := range DefaultNameRules {
+		if actual := rule.Re.ReplaceAllString("2D_arm", rule.Replacement); actual != "2D_arm" {
+			t.Fatalf("Rule `%v` rewrote part of a word: %v", rule.Name, actual)
+		}
+	}
+
+	rules, err := ParseNameRules(strings.NewReader("# Random seeds.\nseed seed_\\d+ seed_<seed>\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalizer := NewNameNormalizer(append(rules, DefaultNameRules...)...)
+	if actual := normalizer.Normalize(pkg + ".TestWeb/seed_42/port_8080"); actual != pkg+".TestWeb/seed_<seed>/port_<n>" {
+		t.Fatalf("Wrong normalization with custom rule: %v", actual)
+	}
+
+	if _, err := ParseNameRules(strings.NewReader("seed seed_\\d+\n")); err == nil {
+		t.Fatal("Expected an error for a rule without a replacement")
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/viamrobotics/bfserver/parser"
+	"github.com/viamrobotics/bfserver/util"
+)
+
+// The parsed test log types. See the `parser` package.
+type (
+	TestLogLine      = parser.TestLogLine
+	FQTest           = parser.FQTest
+	AssertionFailure = parser.AssertionFailure
+	DataraceFailure  = parser.DataraceFailure
+	Datarace         = parser.Datarace
+	TimeoutFailure   = parser.TimeoutFailure
+	RunningTest      = parser.RunningTest
+	CrashFailure     = parser.CrashFailure
+	LeakFailure      = parser.LeakFailure
+	FuzzFailure      = parser.FuzzFailure
+	JSFailure        = parser.JSFailure
+	ResourceFailure  = parser.ResourceFailure
+	BuildFailure     = parser.BuildFailure
+	CompileError     = parser.CompileError
+	UnknownFailure   = parser.UnknownFailure
+	Goroutine        = parser.Goroutine
+	StackFrame       = parser.StackFrame
+	LogEntry         = parser.LogEntry
+	TestRecord       = parser.TestRecord
+	PackageRecord    = parser.PackageRecord
+	SkippedTest      = parser.SkippedTest
+	Flake            = parser.Flake
+	Diagnostics      = parser.Diagnostics
+)
+
+// The parse results of one test job's log, plus how they are presented on the command line.
+type Output struct {
+	*parser.Result
+}
+
+func NewTestSummary() *Output {
+	return &Output{parser.NewResult()}
+}
+
+// Tests whose `Expected`/`Actual` output is not an assertion failure.
+var ignoreAssertionsIn = []string{"TestSabertooth"}
+
+func parseOptions() parser.Options {
+	options := parser.Options{IgnoreAssertionsIn: ignoreAssertionsIn}
+	if util.GDebug {
+		options.Debugf = func(format string, args ...any) {
+			fmt.Printf(format+"\n", args...)
+		}
+	}
+
+	return options
+}
+
+func parseFailures(ctx context.Context, logContents io.Reader) (*Output, error) {
+	return wrapResult(parser.Parse(ctx, logContents, parseOptions()))
+}
+
+func parseJUnit(ctx context.Context, reader io.Reader) (*Output, error) {
+	return wrapResult(parser.ParseJUnit(ctx, reader, parseOptions()))
+}
+
+// Parses a test2json log, JUnit XML or a Jest/Vitest report. See `parser.ParseFile`.
+func parseTestResults(ctx context.Context, fileName string, contents io.Reader) (*Output, error) {
+	return wrapResult(parser.ParseFile(ctx, fileName, contents, parseOptions()))
+}
+
+func wrapResult(result *parser.Result, err error) (*Output, error) {
+	if result == nil {
+		return nil, err
+	}
+
+	return &Output{result}, err
+}
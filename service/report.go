@@ -0,0 +1,297 @@
+package service
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/viamrobotics/bfserver/parser"
+)
+
+// The formats `analyze` can write its results in.
+const (
+	ReportFormatJUnit    = "junit"
+	ReportFormatMarkdown = "markdown"
+	ReportFormatJSON     = "json"
+)
+
+var ReportFormats = []string{ReportFormatJUnit, ReportFormatMarkdown, ReportFormatJSON}
+
+// The failure categories, in the order they are checked. See `classify`.
+const (
+	CategoryFuzz         = "fuzz"
+	CategoryJS           = "js"
+	CategoryAssertion    = "assertion"
+	CategoryTimeout      = "timeout"
+	CategoryDatarace     = "datarace"
+	CategoryRuntimeError = "runtime_error"
+	CategoryCrash        = "crash"
+	CategoryLeak         = "leak"
+	CategoryResource     = "resource"
+	CategoryBuild        = "build"
+	CategoryUnknown      = "unknown"
+)
+
+// The most bytes of logs, and of the failure message, a Markdown report shows per failure. A github
+// step summary is limited to 1MiB.
+const markdownLogLimit = 8000
+
+// A failure of one test, as reported by `analyze`.
+type ReportFailure struct {
+	Test     FQTest
+	Category string
+	// The jira ticket summary. E.g: `Test Failure: go.viam.com/rdk/foo.TestFoo`.
+	Summary string
+	// Where the failure happened, if known.
+	File     string
+	Line     int
+	CodeLink string
+	Message  string
+	Logs     []string
+	// Set for tests that passed on a rerun. E.g: `flaked, passed on rerun 1`.
+	Flake string
+	// Set if the test reported an unexpected error rather than a failure. E.g: a JUnit `<error>`.
+	Error bool
+}
+
+// The failures of one variant (e.g: `amd64`) of a run.
+type ReportVariant struct {
+	Variant    string
+	GithubLink string
+	GitHash    string
+	Failures   []ReportFailure
+}
+
+// Returns the location of a failure of the category and a description of what failed.
+func failureDetails(runFailure Failure, fqTest FQTest, category string) (file string, line int, codeLink, message string) {
+	artifacts := runFailure.Output
+	switch category {
+	case CategoryFuzz:
+		return "", 0, "", artifacts.Fuzz[fqTest].ToPrettyString("")
+	case CategoryJS:
+		jsFailure := artifacts.JSFailures[fqTest]
+		return jsFailure.File, jsFailure.Line,
+			GetJSCodeLink(jsFailure, runFailure.GetRepo(), runFailure.GitHash), jsFailure.ToPrettyString("")
+	case CategoryAssertion:
+		assertion := artifacts.Assertions[fqTest][0]
+		return assertion.File, assertion.Line,
+			GetAssertionCodeLink(assertion, runFailure.GetRepo(), runFailure.GitHash), assertion.ToPrettyString("")
+	case CategoryTimeout:
+		return "", 0, "", artifacts.Timeouts[fqTest].ToPrettyString()
+	case CategoryDatarace:
+		return "", 0, "", artifacts.Dataraces[fqTest].ToPrettyString("")
+	case CategoryRuntimeError:
+		return "", 0, "", artifacts.RuntimeErrors[fqTest].ToPrettyString("")
+	case CategoryCrash:
+		return "", 0, "", artifacts.Crashes[fqTest].ToPrettyString("")
+	case CategoryLeak:
+		return "", 0, "", artifacts.Leaks[fqTest].ToPrettyString("")
+	case CategoryResource:
+		return "", 0, "", artifacts.ResourceFailures[fqTest].ToPrettyString("")
+	case CategoryBuild:
+		buildFailure := artifacts.BuildFailures[fqTest]
+		if len(buildFailure.Errors) > 0 {
+			file, line = buildFailure.Errors[0].File, buildFailure.Errors[0].Line
+		}
+		return file, line, "", buildFailure.ToPrettyString("")
+	}
+
+	message = strings.Join(artifacts.Logs[fqTest], "\n")
+	if unknownFailure := artifacts.UnknownFailures[fqTest]; unknownFailure != nil {
+		message = strings.Join(unknownFailure.LogLines, "\n")
+	}
+	return "", 0, "", message
+}
+
+func NewReportVariant(runFailure Failure) ReportVariant {
+	ret := ReportVariant{
+		Variant:    runFailure.Variant,
+		GithubLink: runFailure.GithubLink,
+		GitHash:    runFailure.GitHash,
+		Failures:   make([]ReportFailure, 0),
+	}
+
+	artifacts := runFailure.Output
+	for _, fqTest := range artifacts.FailuresToTicket() {
+		category, summary, _ := artifacts.classify(fqTest)
+		file, line, codeLink, message := failureDetails(runFailure, fqTest, category)
+
+		reportFailure := ReportFailure{
+			Test:     fqTest,
+			Category: category,
+			Summary:  summary,
+			File:     file,
+			Line:     line,
+			CodeLink: codeLink,
+			Message:  message,
+			Logs:     artifacts.Logs[fqTest],
+			Error:    len(artifacts.JUnitErrors[fqTest]) > 0,
+		}
+		if flake := artifacts.Flakes[fqTest]; flake != nil {
+			reportFailure.Flake = flake.ToPrettyString()
+		}
+		ret.Failures = append(ret.Failures, reportFailure)
+	}
+
+	return ret
+}
+
+// Writes the failures of each variant of the run in the format. See `ReportFormats`. The JSON
+// format is a `RunReport`.
+func WriteReport(writer io.Writer, format string, repo string, runId int64, failures []Failure) error {
+	variants := make([]ReportVariant, 0, len(failures))
+	for _, failure := range failures {
+		variants = append(variants, NewReportVariant(failure))
+	}
+
+	switch format {
+	case ReportFormatJUnit:
+		return writeJUnitReport(writer, failures, variants)
+	case ReportFormatMarkdown:
+		return writeMarkdownReport(writer, variants)
+	case ReportFormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(NewRunReport(repo, runId, failures))
+	default:
+		return fmt.Errorf("Unknown report format: `%v`. Expected one of: %v", format, strings.Join(ReportFormats, ", "))
+	}
+}
+
+// Writes a testsuite per variant. Every test in the logs is a testcase, such that CI UIs can show
+// pass rates. Package level failures (e.g: a build failure) are testcases named after the package.
+func writeJUnitReport(writer io.Writer, failures []Failure, variants []ReportVariant) error {
+	report := parser.JUnitTestSuites{}
+	for idx, variant := range variants {
+		output := failures[idx].Output
+		reportFailures := make(map[FQTest]ReportFailure)
+		for _, reportFailure := range variant.Failures {
+			reportFailures[reportFailure.Test] = reportFailure
+		}
+
+		suite := parser.JUnitTestSuite{Name: variant.Variant}
+		addCase := func(fqTest FQTest, classname, name string, elapsed float64, status string) {
+			testCase := parser.JUnitTestCase{Name: name, Classname: classname, Time: fmt.Sprintf("%.3f", elapsed)}
+			reportFailure, exists := reportFailures[fqTest]
+			switch {
+			case exists && reportFailure.Flake != "":
+				testCase.SystemOut = reportFailure.Flake
+			case exists:
+				result := []parser.JUnitResult{{
+					Message: reportFailure.Summary,
+					Type:    reportFailure.Category,
+					Body:    reportFailure.Message,
+				}}
+				if reportFailure.Error {
+					testCase.Errors = result
+					suite.Errors++
+				} else {
+					testCase.Failures = result
+					suite.Failures++
+				}
+				testCase.SystemOut = strings.Join(reportFailure.Logs, "\n")
+			case status == "pass":
+			case status == "skip":
+				testCase.Skipped = &parser.JUnitResult{}
+				if skip := output.Skips[fqTest]; skip != nil {
+					testCase.Skipped.Message = skip.Reason
+				}
+				suite.Skipped++
+			case status == "fail":
+				// E.g: a parent whose failure is accounted for by its failing subtests.
+				testCase.Failures = []parser.JUnitResult{{Message: "failed because of subtests"}}
+				suite.Failures++
+			default:
+				// E.g: the log was cut off while the test was still running.
+				testCase.Errors = []parser.JUnitResult{{Message: "did not finish"}}
+				suite.Errors++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+			suite.Tests++
+		}
+
+		tests := make([]*TestRecord, 0, len(output.Tests))
+		for _, record := range output.Tests {
+			tests = append(tests, record)
+		}
+		sort.Slice(tests, func(left, right int) bool {
+			return tests[left].ToFQTest() < tests[right].ToFQTest()
+		})
+		for _, record := range tests {
+			addCase(record.ToFQTest(), record.Package, record.Name, record.Elapsed, record.Status)
+		}
+
+		for _, reportFailure := range variant.Failures {
+			if _, exists := output.Tests[reportFailure.Test]; !exists {
+				addCase(reportFailure.Test, string(reportFailure.Test), string(reportFailure.Test), 0, "fail")
+			}
+		}
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// Writes a table of the failures, followed by a collapsible section per failure with its logs.
+// Suited for `$GITHUB_STEP_SUMMARY`.
+func writeMarkdownReport(writer io.Writer, variants []ReportVariant) error {
+	var ret strings.Builder
+	for _, variant := range variants {
+		fmt.Fprintf(&ret, "## %v\n\n", variant.Variant)
+		if variant.GithubLink != "" {
+			fmt.Fprintf(&ret, "[Github Run](%v)\n\n", variant.GithubLink)
+		}
+		if len(variant.Failures) == 0 {
+			ret.WriteString("No failures.\n\n")
+			continue
+		}
+
+		ret.WriteString("| Test | Failure | Location |\n|---|---|---|\n")
+		for _, failure := range variant.Failures {
+			location := ""
+			switch {
+			case failure.CodeLink != "":
+				location = fmt.Sprintf("[%v:%d](%v)", failure.File, failure.Line, failure.CodeLink)
+			case failure.File != "":
+				location = fmt.Sprintf("%v:%d", failure.File, failure.Line)
+			}
+			kind := failure.Category
+			if failure.Error {
+				kind = fmt.Sprintf("%v error", kind)
+			}
+			if failure.Flake != "" {
+				kind = fmt.Sprintf("%v (%v)", kind, failure.Flake)
+			}
+			fmt.Fprintf(&ret, "| `%v` | %v | %v |\n", failure.Test, kind, location)
+		}
+		ret.WriteString("\n")
+
+		for _, failure := range variant.Failures {
+			logs := excerpt(failure.Logs, markdownLogLimit, parser.FocusLines(failure.Logs))
+			fmt.Fprintf(&ret, "<details>\n<summary>%v</summary>\n\n", html.EscapeString(failure.Summary))
+			message := excerpt(strings.Split(failure.Message, "\n"), markdownLogLimit, nil)
+			fmt.Fprintf(&ret, "```\n%v\n```\n\n", message)
+			if logs != "" {
+				fmt.Fprintf(&ret, "Logs:\n\n```\n%v\n```\n\n", logs)
+			}
+			ret.WriteString("</details>\n\n")
+		}
+	}
+
+	_, err := io.WriteString(writer, ret.String())
+	return err
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestWriteReport(t *testing.T) {
+	const pkg = "go.viam.com/rdk/components/arm"
+	output := func(test, line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+	logs, err := parseFailures(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArmPosition"},
+		output("TestArmPosition", "=== RUN   TestArmPosition"),
+		output("TestArmPosition", "    arm_test.go:42: Expected: 1"),
+		output("TestArmPosition", "        Actual:   2"),
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestArmPosition"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArmMove"},
+		TestLogLine{Action: "pass", Package: pkg, Test: "TestArmMove", Elapsed: 1.5},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArmSim"},
+		output("TestArmSim", "    arm_test.go:99: flaky, see RSDK-1234"),
+		TestLogLine{Action: "skip", Package: pkg, Test: "TestArmSim"},
+		TestLogLine{Action: "fail", Package: pkg},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := []Failure{{Variant: "amd64", GithubLink: "https://github.com/viamrobotics/rdk/actions/runs/1/job/2", Output: logs}}
+
+	var jsonReport bytes.Buffer
+	if err := WriteReport(&jsonReport, ReportFormatJSON, "rdk", 1, failures); err != nil {
+		t.Fatal(err)
+	}
+	var runReport RunReport
+	if err := json.Unmarshal(jsonReport.Bytes(), &runReport); err != nil {
+		t.Fatal(err)
+	}
+	if len(runReport.Variants) != 1 || len(runReport.Variants[0].Failures) != 1 {
+		t.Fatalf("Wrong JSON report: %+v", runReport)
+	}
+	if failure := runReport.Variants[0].Failures[0]; failure.Type != CategoryAssertion || failure.Location == nil ||
+		failure.Location.Line != 42 || failure.Summary != "Test Failure: "+pkg+".TestArmPosition" {
+		t.Fatalf("Wrong failure: %+v", failure)
+	}
+
+	// The JUnit report reads back as the same results.
+	var junitReport bytes.Buffer
+	if err := WriteReport(&junitReport, ReportFormatJUnit, "rdk", 1, failures); err != nil {
+		t.Fatal(err)
+	}
+	reread, err := parseJUnit(context.Background(), &junitReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reread.TestFailures) != 1 || reread.TestFailures[0] != "amd64.TestArmPosition" {
+		t.Fatalf("Wrong JUnit failures: %v\n%v", reread.TestFailures, junitReport.String())
+	}
+	if len(reread.Tests) != 3 || reread.Skips["amd64.TestArmSim"] == nil ||
+		reread.Skips["amd64.TestArmSim"].Reason != "flaky, see RSDK-1234" {
+		t.Fatalf("Wrong JUnit tests: %v Skips: %v", reread.Tests, reread.Skips)
+	}
+
+	var markdownReport bytes.Buffer
+	if err := WriteReport(&markdownReport, ReportFormatMarkdown, "rdk", 1, failures); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"## amd64",
+		"| `" + pkg + ".TestArmPosition` | assertion | arm_test.go:42 |",
+		"<summary>Test Failure: " + pkg + ".TestArmPosition</summary>",
+		"Expected: 1",
+	} {
+		if !strings.Contains(markdownReport.String(), expected) {
+			t.Fatalf("Expected the Markdown report to contain `%v`. Actual:\n%v", expected, markdownReport.String())
+		}
+	}
+
+	if err := WriteReport(&markdownReport, "html", "rdk", 1, failures); err == nil {
+		t.Fatalf("Expected an error for an unknown format.")
+	}
+}
+
+// JUnit `<error>`s stay errors in the reports.
+func TestWriteReportJUnitError(t *testing.T) {
+	logs, err := parseJUnit(context.Background(), strings.NewReader(`<testsuite name="e2e">
+  <testcase classname="e2e" name="TestLogin" time="0.1">
+    <error message="connection refused" type="NetError">dial tcp 127.0.0.1:8080</error>
+  </testcase>
+</testsuite>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := []Failure{{Variant: "e2e", GithubLink: "https://github.com/viamrobotics/app/actions/runs/1", Output: logs}}
+
+	var junitReport bytes.Buffer
+	if err := WriteReport(&junitReport, ReportFormatJUnit, "app", 1, failures); err != nil {
+		t.Fatal(err)
+	}
+	reread, err := parseJUnit(context.Background(), &junitReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errors := reread.JUnitErrors["e2e.TestLogin"]; len(errors) != 1 || errors[0].Type != CategoryUnknown {
+		t.Fatalf("Expected the error to be written as an error. Actual:\n%v", junitReport.String())
+	}
+
+	var markdownReport bytes.Buffer
+	if err := WriteReport(&markdownReport, ReportFormatMarkdown, "app", 1, failures); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "| `e2e.TestLogin` | unknown error |"; !strings.Contains(markdownReport.String(), expected) {
+		t.Fatalf("Expected the Markdown report to contain `%v`. Actual:\n%v", expected, markdownReport.String())
+	}
+}
+
+// Tests without a failure to report are written by how they finished.
+func TestWriteReportJUnitStatuses(t *testing.T) {
+	const pkg = "go.viam.com/rdk/components/arm"
+	logs, err := parseFailures(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArm"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArm/Move"},
+		TestLogLine{Action: "output", Package: pkg, Test: "TestArm/Move", Output: "    arm_test.go:42: Expected: 1\n"},
+		TestLogLine{Action: "output", Package: pkg, Test: "TestArm/Move", Output: "        Actual:   2\n"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestArm/Move"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestArm"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArmStop"},
+		TestLogLine{Action: "pass", Package: pkg, Test: "TestArmStop"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArmHome"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := []Failure{{Variant: "amd64", Output: logs}}
+
+	var junitReport bytes.Buffer
+	if err := WriteReport(&junitReport, ReportFormatJUnit, "rdk", 1, failures); err != nil {
+		t.Fatal(err)
+	}
+	reread, err := parseJUnit(context.Background(), bytes.NewReader(junitReport.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record := reread.Tests["amd64.TestArm"]; record == nil || record.Status != "fail" ||
+		!strings.Contains(junitReport.String(), `<failure message="failed because of subtests">`) {
+		t.Fatalf("Expected the parent to fail because of its subtests. Actual:\n%v", junitReport.String())
+	}
+	if errors := reread.JUnitErrors["amd64.TestArmHome"]; len(errors) != 1 {
+		t.Fatalf("Expected the unfinished test to be an error. Actual:\n%v", junitReport.String())
+	}
+	if record := reread.Tests["amd64.TestArmStop"]; record == nil || record.Status != "pass" {
+		t.Fatalf("Expected the passing test to pass. Actual:\n%v", junitReport.String())
+	}
+}
+
+// A github step summary is limited in size. Long failure messages are cut down.
+func TestWriteMarkdownReportLongMessage(t *testing.T) {
+	lines := make([]string, 0)
+	for idx := 0; idx < 10000; idx++ {
+		lines = append(lines, "Expected: a very long value")
+	}
+	variants := []ReportVariant{{Variant: "amd64", Failures: []ReportFailure{{
+		Test:     "go.viam.com/rdk/components/arm.TestArm",
+		Category: CategoryAssertion,
+		Summary:  "Test Failure: go.viam.com/rdk/components/arm.TestArm",
+		Message:  strings.Join(lines, "\n"),
+	}}}}
+
+	var markdownReport bytes.Buffer
+	if err := writeMarkdownReport(&markdownReport, variants); err != nil {
+		t.Fatal(err)
+	}
+	if markdownReport.Len() > 2*markdownLogLimit || !strings.Contains(markdownReport.String(), "lines elided") {
+		t.Fatalf("Expected the message to be cut down. Size: %v", markdownReport.Len())
+	}
+}
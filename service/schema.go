@@ -0,0 +1,183 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/viamrobotics/bfserver/parser"
+)
+
+// The version of the `RunReport` JSON schema. Fields may be added without bumping the version.
+// Renaming, removing or changing the meaning of a field bumps it.
+const RunReportSchemaVersion = 1
+
+// The JSON representation of one analyzed github run, as written by `analyze --json` and served
+// by `BFServer`. E.g:
+//
+//	{
+//	  "schemaVersion": 1,
+//	  "run": {"repo": "rdk", "id": 5859328480, "url": "https://github.com/...", "headSha": "abc123", ...},
+//	  "variants": [{
+//	    "name": "amd64",
+//	    "jobUrl": "https://github.com/viamrobotics/rdk/actions/runs/5859328480/job/15885094207",
+//	    "success": false,
+//	    "failures": [{
+//	      "type": "assertion",
+//	      "test": "go.viam.com/rdk/components/arm.TestArmPosition",
+//	      "package": "go.viam.com/rdk/components/arm",
+//	      "summary": "Test Failure: go.viam.com/rdk/components/arm.TestArmPosition",
+//	      "location": {"file": "arm_test.go", "line": 42, "url": "https://github.com/..."},
+//	      "message": "...",
+//	      "excerpt": "...",
+//	      "fingerprint": "5d41402abc4b2a76"
+//	    }]
+//	  }]
+//	}
+type RunReport struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Run           RunMetadata     `json:"run"`
+	Variants      []VariantReport `json:"variants"`
+}
+
+type RunMetadata struct {
+	// The repository's short name. E.g: `rdk`.
+	Repo string `json:"repo"`
+	ID   int64  `json:"id"`
+	URL  string `json:"url,omitempty"`
+	// The workflow's name. E.g: `Build and Publish RC`.
+	Workflow string `json:"workflow,omitempty"`
+	// E.g: `push` or `pull_request`.
+	Event      string     `json:"event,omitempty"`
+	Branch     string     `json:"branch,omitempty"`
+	HeadSha    string     `json:"headSha,omitempty"`
+	Conclusion string     `json:"conclusion,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+}
+
+// The results of one test job of the run. E.g: `amd64`.
+type VariantReport struct {
+	Name    string `json:"name"`
+	JobURL  string `json:"jobUrl"`
+	GitHash string `json:"gitHash,omitempty"`
+	// False if any test failed, other than flakes.
+	Success  bool            `json:"success"`
+	Failures []FailureReport `json:"failures"`
+	// The number of lines that were not test results. See `Diagnostics`.
+	SkippedLines int `json:"skippedLines,omitempty"`
+}
+
+type FailureReport struct {
+	// One of the `Category*` constants. E.g: `assertion` or `timeout`.
+	Type string `json:"type"`
+	// E.g: `go.viam.com/rdk/components/arm.TestArmPosition`. For package level failures (e.g: a
+	// build failure), the package.
+	Test    string `json:"test"`
+	Package string `json:"package"`
+	// The jira ticket summary.
+	Summary  string    `json:"summary"`
+	Location *Location `json:"location,omitempty"`
+	Message  string    `json:"message"`
+	// The test's logs, cut down around the failure. See `excerpt`.
+	Excerpt string `json:"excerpt"`
+	// Identifies the failure across runs. Equal for failures that would be filed as the same
+	// ticket.
+	Fingerprint string `json:"fingerprint"`
+	// Set for tests that failed and then passed on a rerun.
+	Flake *FlakeReport `json:"flake,omitempty"`
+	// True if the test reported an unexpected error rather than a failure. E.g: a JUnit `<error>`.
+	Error bool `json:"error,omitempty"`
+}
+
+type Location struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// A link to the line on github, if known.
+	URL string `json:"url,omitempty"`
+}
+
+type FlakeReport struct {
+	Attempts      int `json:"attempts"`
+	PassedOnRerun int `json:"passedOnRerun"`
+}
+
+// The most bytes of logs kept in a failure's excerpt.
+const reportExcerptLimit = 8000
+
+// The number of hex characters of a fingerprint.
+const fingerprintLength = 16
+
+// Returns a stable identifier for the failure. Failures with signatures (e.g: a datarace) are
+// identified by the signatures, such that the same race hit by different tests is one failure.
+// Others are identified by their summary.
+func failureFingerprint(summary string, signatures []string) string {
+	parts := []string{summary}
+	if len(signatures) > 0 {
+		parts = append([]string{}, signatures...)
+		sort.Strings(parts)
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(hash[:])[:fingerprintLength]
+}
+
+// Builds the report of a run from the failures of each of its variants. The `repo` and `runId`
+// are used when there are no failures to take the run's metadata from.
+func NewRunReport(repo string, runId int64, failures []Failure) *RunReport {
+	ret := &RunReport{
+		SchemaVersion: RunReportSchemaVersion,
+		Run:           RunMetadata{Repo: repo, ID: runId},
+		Variants:      make([]VariantReport, 0, len(failures)),
+	}
+	if len(failures) > 0 && failures[0].WorkflowRun != nil {
+		workflowRun := failures[0].WorkflowRun
+		ret.Run.URL = workflowRun.GetHTMLURL()
+		ret.Run.Workflow = workflowRun.GetName()
+		ret.Run.Event = workflowRun.GetEvent()
+		ret.Run.Branch = workflowRun.GetHeadBranch()
+		ret.Run.HeadSha = workflowRun.GetHeadSHA()
+		ret.Run.Conclusion = workflowRun.GetConclusion()
+		if startedAt := workflowRun.GetRunStartedAt(); !startedAt.IsZero() {
+			ret.Run.StartedAt = &startedAt.Time
+		}
+	}
+
+	for _, failure := range failures {
+		variant := VariantReport{
+			Name:         failure.Variant,
+			JobURL:       failure.GithubLink,
+			GitHash:      failure.GitHash,
+			Success:      failure.Output.IsSuccess(),
+			Failures:     make([]FailureReport, 0),
+			SkippedLines: failure.Output.Diagnostics.NumSkippedLines(),
+		}
+		for _, reportFailure := range NewReportVariant(failure).Failures {
+			failureReport := FailureReport{
+				Type:        reportFailure.Category,
+				Test:        string(reportFailure.Test),
+				Package:     failure.Output.PackageOf(reportFailure.Test),
+				Summary:     reportFailure.Summary,
+				Message:     reportFailure.Message,
+				Excerpt:     excerpt(reportFailure.Logs, reportExcerptLimit, parser.FocusLines(reportFailure.Logs)),
+				Fingerprint: failureFingerprint(reportFailure.Summary, GetSignaturesForFailure(failure, reportFailure.Test)),
+				Error:       reportFailure.Error,
+			}
+			if reportFailure.File != "" {
+				failureReport.Location = &Location{
+					File: reportFailure.File,
+					Line: reportFailure.Line,
+					URL:  reportFailure.CodeLink,
+				}
+			}
+			if flake := failure.Output.Flakes[reportFailure.Test]; flake != nil {
+				failureReport.Flake = &FlakeReport{Attempts: flake.Attempts, PassedOnRerun: flake.PassedOnRerun()}
+			}
+			variant.Failures = append(variant.Failures, failureReport)
+		}
+		ret.Variants = append(ret.Variants, variant)
+	}
+
+	return ret
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func schemaTestFailures(t *testing.T) []Failure {
+	const pkg = "go.viam.com/rdk/components/arm"
+	output := func(test, line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+	logs, err := parseFailures(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArmPosition"},
+		output("TestArmPosition", "=== RUN   TestArmPosition"),
+		output("TestArmPosition", "    arm_test.go:42: Expected: 1"),
+		output("TestArmPosition", "        Actual:   2"),
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestArmPosition"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArmMove"},
+		output("TestArmMove", "--- FAIL: TestArmMove (0.10s)"),
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestArmMove"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestArmMove"},
+		TestLogLine{Action: "pass", Package: pkg, Test: "TestArmMove"},
+		TestLogLine{Action: "fail", Package: pkg},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workflowRun := &github.WorkflowRun{
+		ID:           github.Int64(5859328480),
+		Name:         github.String("Build and Publish RC"),
+		HTMLURL:      github.String("https://github.com/viamrobotics/rdk/actions/runs/5859328480"),
+		HeadSHA:      github.String("abc123"),
+		Conclusion:   github.String("failure"),
+		RunStartedAt: &github.Timestamp{Time: time.Date(2023, 8, 1, 20, 15, 1, 0, time.UTC)},
+		Repository:   &github.Repository{Name: github.String("rdk")},
+	}
+
+	return []Failure{{
+		Variant:     "amd64",
+		GithubLink:  "https://github.com/viamrobotics/rdk/actions/runs/5859328480/job/15885094207",
+		GitHash:     "abc123",
+		Output:      logs,
+		WorkflowRun: workflowRun,
+	}}
+}
+
+func TestRunReportRoundTrip(t *testing.T) {
+	report := NewRunReport("rdk", 5859328480, schemaTestFailures(t))
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded RunReport
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*report, decoded) {
+		t.Fatalf("Report changed in a round trip.\nExpected: %+v\nActual:   %+v", *report, decoded)
+	}
+
+	if decoded.SchemaVersion != RunReportSchemaVersion || decoded.Run.Workflow != "Build and Publish RC" ||
+		decoded.Run.StartedAt == nil || len(decoded.Variants) != 1 {
+		t.Fatalf("Wrong run: %+v", decoded)
+	}
+	variant := decoded.Variants[0]
+	if variant.Success || len(variant.Failures) != 2 {
+		t.Fatalf("Wrong variant: %+v", variant)
+	}
+
+	// Sorted by test name.
+	flake, assertion := variant.Failures[0], variant.Failures[1]
+	if assertion.Test != "go.viam.com/rdk/components/arm.TestArmPosition" || assertion.Type != CategoryAssertion ||
+		assertion.Package != "go.viam.com/rdk/components/arm" || assertion.Location == nil ||
+		assertion.Location.Line != 42 || !strings.HasPrefix(assertion.Location.URL, "https://github.com/viamrobotics/rdk/blob/abc123/") ||
+		!strings.Contains(assertion.Excerpt, "Expected: 1") || len(assertion.Fingerprint) != fingerprintLength {
+		t.Fatalf("Wrong assertion: %+v", assertion)
+	}
+	if flake.Flake == nil || flake.Flake.PassedOnRerun != 1 || flake.Type != CategoryUnknown {
+		t.Fatalf("Wrong flake: %+v", flake)
+	}
+}
+
+// A failure's package is never derived from its test name. E.g: a crash blamed on a test that has
+// no record, as the log has no `run` line for it.
+func TestRunReportPackage(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	packageOutput := func(output string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Output: output + "\n"}
+	}
+	logs, err := parseFailures(context.Background(), parsertest.LogReader(
+		packageOutput("fatal error: concurrent map writes"),
+		packageOutput("running tests:"),
+		packageOutput("\tTestConcurrentWrites (3s)"),
+		TestLogLine{Action: "fail", Package: pkg, Elapsed: 3},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := NewRunReport("rdk", 1, []Failure{{Variant: "amd64", Output: logs}})
+	if failures := report.Variants[0].Failures; len(failures) != 1 ||
+		failures[0].Test != pkg+".TestConcurrentWrites" || failures[0].Package != pkg {
+		t.Fatalf("Wrong failures: %+v", failures)
+	}
+}
+
+// Consumers depend on the field names. Changing them requires bumping `RunReportSchemaVersion`.
+func TestRunReportFieldNames(t *testing.T) {
+	encoded, err := json.Marshal(NewRunReport("rdk", 5859328480, schemaTestFailures(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatal(err)
+	}
+	keys := func(value any) []string {
+		ret := make([]string, 0)
+		for key := range value.(map[string]any) {
+			ret = append(ret, key)
+		}
+		sort.Strings(ret)
+		return ret
+	}
+
+	run := fields["run"]
+	variant := fields["variants"].([]any)[0]
+	// The assertion. The flake also has a `flake` field.
+	failure := variant.(map[string]any)["failures"].([]any)[1]
+	for _, expected := range []struct {
+		name     string
+		actual   []string
+		expected []string
+	}{
+		{"report", keys(fields), []string{"run", "schemaVersion", "variants"}},
+		{"run", keys(run), []string{"conclusion", "headSha", "id", "repo", "startedAt", "url", "workflow"}},
+		{"variant", keys(variant), []string{"failures", "gitHash", "jobUrl", "name", "success"}},
+		{"failure", keys(failure), []string{
+			"excerpt", "fingerprint", "location", "message", "package", "summary", "test", "type"}},
+	} {
+		if !reflect.DeepEqual(expected.actual, expected.expected) {
+			t.Fatalf("Wrong %v fields. Expected: %v Actual: %v", expected.name, expected.expected, expected.actual)
+		}
+	}
+}
+
+func TestFailureFingerprint(t *testing.T) {
+	if failureFingerprint("Test Failure: foo.TestFoo", nil) != failureFingerprint("Test Failure: foo.TestFoo", nil) {
+		t.Fatalf("Expected fingerprints to be stable.")
+	}
+	if failureFingerprint("Test Failure: foo.TestFoo", nil) == failureFingerprint("Test Failure: foo.TestBar", nil) {
+		t.Fatalf("Expected different summaries to have different fingerprints.")
+	}
+
+	// The same race hit by different tests.
+	signatures := []string{"Datarace signature: a", "Datarace signature: b"}
+	if failureFingerprint("Test Datarace: foo.TestFoo", signatures) !=
+		failureFingerprint("Test Datarace: foo.TestBar", []string{signatures[1], signatures[0]}) {
+		t.Fatalf("Expected failures with the same signatures to have the same fingerprint.")
+	}
+}
+
+func TestRunReportAPI(t *testing.T) {
+	server := NewBFServer(nil)
+	server.runFailures = func(ctx context.Context, repo string, runId int64) ([]Failure, error) {
+		switch runId {
+		case 5859328480:
+		case 404:
+			return nil, &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+		default:
+			return nil, errors.New("github unavailable")
+		}
+		return schemaTestFailures(t), nil
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/runs/rdk/5859328480", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Wrong response: %v %v", recorder.Code, recorder.Body.String())
+	}
+	var report RunReport
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Run.Repo != "rdk" || report.Run.ID != 5859328480 || len(report.Variants) != 1 {
+		t.Fatalf("Wrong report: %+v", report)
+	}
+
+	for _, request := range []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodGet, "/api/v1/runs/rdk/1", http.StatusBadGateway},
+		{http.MethodGet, "/api/v1/runs/rdk/404", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/runs/notarepo/5859328480", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/runs/rdk", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/runs/rdk/5859328480", http.StatusMethodNotAllowed},
+	} {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(request.method, request.path, nil))
+		if recorder.Code != request.status {
+			t.Fatalf("Wrong status for %v %v. Expected: %v Actual: %v", request.method, request.path,
+				request.status, recorder.Code)
+		}
+	}
+}
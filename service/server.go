@@ -4,16 +4,12 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"regexp"
-	"sort"
 	"strconv"
 	"strings"
-	"unicode"
 
 	"github.com/google/go-github/v61/github"
 	"github.com/viamrobotics/bfserver/util"
@@ -28,19 +24,50 @@ func GithubRate() github.Rate {
 	return lastResponse.Rate
 }
 
-// Removes white-space from the end of a string
-func trimRightSpace(str string) string {
-	return strings.TrimRightFunc(str, unicode.IsSpace)
+type BFServer struct {
+	client *github.Client
+	// Returns the test failures of a run. Replaced in tests.
+	runFailures func(ctx context.Context, repo string, runId int64) ([]Failure, error)
 }
 
-type BFServer struct {
+func NewBFServer(client *github.Client) *BFServer {
+	ret := &BFServer{client: client}
+	ret.runFailures = ret.githubRunFailures
+	return ret
+}
+
+type WID struct {
+	repo string
+	name string
+	id   int64
+
+	onlyPush bool
 }
 
-func NewBFServer() *BFServer {
-	return &BFServer{}
+// The workflows whose runs are analyzed. See `FindFailingRuns` for how to find a workflow's ID.
+var testedWorkflows = []WID{
+	WID{"rdk", "Build and Publish Latest", 17922513, true},
+	WID{"rdk", "Build and Publish Stable", 56642554, true},
+	WID{"rdk", "Docker", 6417489, false},
+	WID{"rdk", "Build and Publish RC", 56639284, false},
+	WID{"app", "Main Branch Update", 20902450, true},
+	WID{"goutils", "Build and Test", 10703570, true},
 }
 
-func FindFailingRuns(ctx context.Context, client *github.Client, startDate, endDate string) ([]*github.WorkflowRun, error) {
+// Returns true if the repo has a workflow in `testedWorkflows`. E.g: `rdk`.
+func isTestedRepo(repo string) bool {
+	for _, workflow := range testedWorkflows {
+		if workflow.repo == repo {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the completed runs of the `testedWorkflows` that failed. With `includeSuccess`, runs that
+// succeeded are also returned. Those can have tests that failed and passed on a rerun.
+func FindFailingRuns(ctx context.Context, client *github.Client, startDate, endDate string, includeSuccess bool) ([]*github.WorkflowRun, error) {
 	service := client.Actions
 
 	/**
@@ -84,23 +111,8 @@ func FindFailingRuns(ctx context.Context, client *github.Client, startDate, endD
 	}
 
 	ret := []*github.WorkflowRun{}
-	type WID struct {
-		repo string
-		name string
-		id   int64
-
-		onlyPush bool
-	}
-
 	// Github pagination starts at Page 1.
-	for _, workflow := range []WID{
-		WID{"rdk", "Build and Publish Latest", 17922513, true},
-		WID{"rdk", "Build and Publish Stable", 56642554, true},
-		WID{"rdk", "Docker", 6417489, false},
-		WID{"rdk", "Build and Publish RC", 56639284, false},
-		WID{"app", "Main Branch Update", 20902450, true},
-		WID{"goutils", "Build and Test", 10703570, true},
-	} {
+	for _, workflow := range testedWorkflows {
 		if util.GDebug {
 			fmt.Printf("Querying: %v/%v\n", workflow.repo, workflow.name)
 		}
@@ -124,7 +136,8 @@ func FindFailingRuns(ctx context.Context, client *github.Client, startDate, endD
 				if util.GDebug {
 					fmt.Println("Run URL:", workflowRun.GetHTMLURL(), "Conclusion", workflowRun.GetConclusion())
 				}
-				if workflowRun.GetConclusion() != "failure" {
+				if workflowRun.GetConclusion() != "failure" &&
+					!(includeSuccess && workflowRun.GetConclusion() == "success") {
 					continue
 				}
 				ret = append(ret, workflowRun)
@@ -139,32 +152,24 @@ func FindFailingRuns(ctx context.Context, client *github.Client, startDate, endD
 	return ret, nil
 }
 
-type Output struct {
-	Assertions    map[FQTest][]AssertionFailure
-	Dataraces     map[FQTest]*DataraceFailure
-	RuntimeErrors map[FQTest]*RuntimeFailure
-	Timeouts      map[FQTest]*TimeoutFailure
-	Logs          map[FQTest][]string
-
-	PackageFailures []TestLogLine
-	TestFailures    []FQTest
-}
-
-func (output *Output) IsSuccess() bool {
-	return (len(output.Assertions) +
-		len(output.Dataraces) +
-		len(output.Timeouts) +
-		len(output.PackageFailures) +
-		len(output.RuntimeErrors) +
-		len(output.TestFailures)) == 0
-}
-
 func (output Output) PrettyPrint(indent string) {
 	for _, testFailure := range output.TestFailures {
 		fmt.Println("Test Error:", testFailure)
+		if ancestry := output.AncestryString(testFailure); ancestry != "" {
+			fmt.Println("Subtest of:", ancestry)
+		}
 		for _, assertion := range output.Assertions[testFailure] {
 			fmt.Println(assertion.ToPrettyString(indent))
 		}
+		if jsFailure := output.JSFailures[testFailure]; jsFailure != nil {
+			fmt.Println(jsFailure.ToPrettyString(indent))
+		}
+
+		// Prefer the structured logs. The full logs are attached to tickets.
+		if logSummary := output.LogSummary(testFailure, indent); len(logSummary) > 0 && !util.GDebug {
+			fmt.Println(strings.Join(logSummary, "\n"))
+			continue
+		}
 
 		for _, logLine := range output.Logs[testFailure] {
 			fmt.Println(logLine)
@@ -173,9 +178,8 @@ func (output Output) PrettyPrint(indent string) {
 
 	for test, timeout := range output.Timeouts {
 		fmt.Println("Timeout Error:", test)
-		for _, line := range timeout.LogLines {
-			fmt.Printf("%v%v", indent, line)
-		}
+		fmt.Println(timeout.ToPrettyString())
+		fmt.Println(timeout.GoroutineSummary(indent))
 
 		for _, logLine := range output.Logs[test] {
 			fmt.Println(logLine)
@@ -184,9 +188,7 @@ func (output Output) PrettyPrint(indent string) {
 
 	for pkg, race := range output.Dataraces {
 		fmt.Println("Datarace Error:", pkg)
-		for _, line := range race.LogLines {
-			fmt.Printf("%v%v", indent, line)
-		}
+		fmt.Println(race.ToPrettyString(indent))
 
 		for _, logLine := range output.Logs[pkg] {
 			fmt.Println(logLine)
@@ -195,18 +197,68 @@ func (output Output) PrettyPrint(indent string) {
 
 	for pkg, runtimeError := range output.RuntimeErrors {
 		fmt.Println("Runtime Error:", pkg)
-		for _, line := range runtimeError.LogLines {
-			fmt.Printf("%v%v", indent, line)
+		fmt.Println(runtimeError.ToPrettyString(indent))
+
+		for _, logLine := range output.Logs[pkg] {
+			fmt.Println(logLine)
 		}
+	}
+
+	for test, crash := range output.Crashes {
+		fmt.Println("Crash Error:", test)
+		fmt.Println(crash.ToPrettyString(indent))
+
+		for _, logLine := range output.Logs[test] {
+			fmt.Println(logLine)
+		}
+	}
+
+	for test, leak := range output.Leaks {
+		fmt.Println("Goroutine Leak Error:", test)
+		fmt.Println(leak.ToPrettyString(indent))
+
+		for _, logLine := range output.Logs[test] {
+			fmt.Println(logLine)
+		}
+	}
+
+	for test, fuzz := range output.Fuzz {
+		fmt.Println("Fuzz Error:", test)
+		fmt.Println(fuzz.ToPrettyString(indent))
+	}
+
+	for pkg, resourceFailure := range output.ResourceFailures {
+		fmt.Println("Resource Error:", pkg)
+		fmt.Println(resourceFailure.ToPrettyString(indent))
 
 		for _, logLine := range output.Logs[pkg] {
 			fmt.Println(logLine)
 		}
 	}
 
+	for pkg, buildFailure := range output.BuildFailures {
+		fmt.Println("Build Error:", pkg)
+		fmt.Println(buildFailure.ToPrettyString(indent))
+	}
+
+	for test, unknownFailure := range output.UnknownFailures {
+		fmt.Println("Unclassified Error:", test)
+		for _, line := range unknownFailure.LogLines {
+			fmt.Printf("%v%v\n", indent, line)
+		}
+	}
+
 	for _, packageFailure := range output.PackageFailures {
 		fmt.Println("Package Error:", packageFailure.ToPackageFailureString())
 	}
+
+	for test, flake := range output.Flakes {
+		fmt.Printf("Flake: %v (%v)\n", test, flake.ToPrettyString())
+	}
+
+	if output.Diagnostics.NumSkippedLines() > 0 {
+		fmt.Println("Parse Diagnostics:", output.Diagnostics.ToPrettyString())
+	}
 }
 
 func (output Output) ThingsThatFailed(indent string, failure Failure) {
@@ -214,10 +266,16 @@ func (output Output) ThingsThatFailed(indent string, failure Failure) {
 		for _, assertion := range assertions {
 			fmt.Printf("%sFailed: %v (%v:%d)\n", indent, test, assertion.File, assertion.Line)
 			fmt.Printf("%s%sCode link: %s\n",
-				indent, "  ", assertion.GetAssertionCodeLink(failure.GetRepo(), failure.GitHash))
+				indent, "  ", GetAssertionCodeLink(assertion, failure.GetRepo(), failure.GitHash))
 		}
 	}
 
+	for test, jsFailure := range output.JSFailures {
+		fmt.Printf("%sFailed: %v (%v:%d)\n", indent, test, jsFailure.File, jsFailure.Line)
+		fmt.Printf("%s%sCode link: %s\n",
+			indent, "  ", GetJSCodeLink(jsFailure, failure.GetRepo(), failure.GitHash))
+	}
+
 	for _, test := range output.Timeouts {
 		fmt.Printf("%sTimeout: %v\n", indent, test)
 	}
@@ -230,45 +288,45 @@ func (output Output) ThingsThatFailed(indent string, failure Failure) {
 		fmt.Printf("%sRuntime Error: %v\n", indent, test)
 	}
 
-	fmt.Println("Debug")
-	for _, test := range output.TestFailures {
-		fmt.Println(test)
+	for test, crash := range output.Crashes {
+		fmt.Printf("%sCrash: %v (%v: %v)\n", indent, test, crash.Kind, crash.Value)
 	}
-}
 
-func NewTestSummary() *Output {
-	return &Output{
-		Assertions:    make(map[FQTest][]AssertionFailure),
-		Dataraces:     make(map[FQTest]*DataraceFailure),
-		RuntimeErrors: make(map[FQTest]*RuntimeFailure),
-		Timeouts:      make(map[FQTest]*TimeoutFailure),
-		Logs:          make(map[FQTest][]string),
+	for test, leak := range output.Leaks {
+		fmt.Printf("%sGoroutine Leak: %v (%d goroutines)\n", indent, test, len(leak.Goroutines))
 	}
-}
 
-type AssertionFailure struct {
-	Package  string
-	File     string
-	Line     int
-	Expected string
-	Actual   string
-}
+	for test, fuzz := range output.Fuzz {
+		fmt.Printf("%sFuzz: %v\n", indent, test)
+		fmt.Printf("%s%sReproduce: %v\n", indent, "  ", fuzz.ReproduceCommand())
+	}
 
-func (failure AssertionFailure) ToPrettyString(indent string) string {
-	switch failure.Actual {
-	case "":
-		return fmt.Sprintf("%sFile:     %s/%s:%d\n%sExpected: %v\n",
-			indent, failure.Package, failure.File, failure.Line,
-			indent, failure.Expected)
-	default:
-		return fmt.Sprintf("%sFile:     %s/%s:%d\n%sExpected: %v\n%sActual:   %v",
-			indent, failure.Package, failure.File, failure.Line,
-			indent, failure.Expected,
-			indent, strings.TrimSpace(failure.Actual))
+	for pkg, resourceFailure := range output.ResourceFailures {
+		fmt.Printf("%sResource Failure: %v (%v)\n", indent, pkg, resourceFailure.Reason)
+	}
+
+	for pkg, buildFailure := range output.BuildFailures {
+		fmt.Printf("%sBuild Failure: %v\n", indent, pkg)
+		for _, compileError := range buildFailure.Errors {
+			fmt.Printf("%s%s%v\n", indent, "  ", compileError.ToPrettyString())
+		}
+	}
+
+	for test := range output.UnknownFailures {
+		fmt.Printf("%sUnclassified: %v\n", indent, test)
+	}
+
+	for test, flake := range output.Flakes {
+		fmt.Printf("%sFlaked: %v (passed on rerun %d)\n", indent, test, flake.PassedOnRerun())
+	}
+
+	fmt.Println("Debug")
+	for _, test := range output.TestFailures {
+		fmt.Println(test)
 	}
 }
 
-func (failure AssertionFailure) GetAssertionCodeLink(repo string, gitHash string) string {
+func GetAssertionCodeLink(failure AssertionFailure, repo string, gitHash string) string {
 	var fullName string
 	switch repo {
 	case "rdk":
@@ -277,9 +335,13 @@ func (failure AssertionFailure) GetAssertionCodeLink(repo string, gitHash string
 		fullName = "go.viam.com/utils/"
 	case "app":
 		fullName = "github.com/viamrobotics/app/"
+	default:
+		return ""
 	}
 	testPkg, found := strings.CutPrefix(failure.Package, fullName)
-	fmt.Printf("Package: %v Fullname: %v Test: %v Found: %v\n", failure.Package, fullName, testPkg, found)
+	if util.GDebug {
+		fmt.Printf("Package: %v Fullname: %v Test: %v Found: %v\n", failure.Package, fullName, testPkg, found)
+	}
 	if !found {
 		return ""
 	}
@@ -287,45 +349,23 @@ func (failure AssertionFailure) GetAssertionCodeLink(repo string, gitHash string
 	return fmt.Sprintf("https://github.com/viamrobotics/%v/blob/%s/%s/%s#L%d", repo, gitHash, testPkg, failure.File, failure.Line)
 }
 
-func (failure AssertionFailure) GetAssertionCodeLinkWithText(linkText string, runFailure Failure) string {
-	return fmt.Sprintf("[%s|%s]", linkText, failure.GetAssertionCodeLink(runFailure.GetRepo(), runFailure.GitHash))
+func GetAssertionCodeLinkWithText(failure AssertionFailure, linkText string, runFailure Failure) string {
+	return fmt.Sprintf("[%s|%s]", linkText, GetAssertionCodeLink(failure, runFailure.GetRepo(), runFailure.GitHash))
 }
 
-type DataraceFailure struct {
-	Package  string
-	LogLines []string
-}
+// E.g: "https://github.com/viamrobotics/app/blob/<hash>/web/src/login.test.ts#L12"
+func GetJSCodeLink(failure *JSFailure, repo string, gitHash string) string {
+	if failure.Line == 0 {
+		return ""
+	}
 
-type RuntimeFailure struct {
-	Package  string
-	LogLines []string
+	return fmt.Sprintf("https://github.com/viamrobotics/%v/blob/%s/%s#L%d", repo, gitHash, failure.File, failure.Line)
 }
 
-type TimeoutFailure struct {
-	LogLines []string
+func GetJSCodeLinkWithText(failure *JSFailure, linkText string, runFailure Failure) string {
+	return fmt.Sprintf("[%s|%s]", linkText, GetJSCodeLink(failure, runFailure.GetRepo(), runFailure.GitHash))
 }
 
-// E.g: "    ur5e_test.go:384: Expected: nil"
-// E.g: "    gpiostepper_test.go:391: Expected '0' to be between '1' and '20000' or equal to one of them (but it wasn't)!"
-var expectedRe *regexp.Regexp = regexp.MustCompile(
-	`^[[:space:]]*(\w+\.go):(\d+): Expected:? (.+)$`)
-
-// E.g: "        Actual:   'timeout'"
-var actualRe *regexp.Regexp = regexp.MustCompile(
-	`^[[:space:]]*Actual:([^\n]+)$`)
-
-// E.g: "panic: test timed out after 10m0s"
-var startTimeoutRe *regexp.Regexp = regexp.MustCompile(
-	`^panic: test timed out after (.*)$`)
-
-// E.g: "FAIL\tgo.viam.com/rdk/services/navigation/builtin\t600.208s"
-var lastTimeoutLogLineRe *regexp.Regexp = regexp.MustCompile(
-	`^FAIL\t(.*)\t(.*)`)
-
-// E.g: "Found 1 data race(s)"
-var lastDataraceLogLineRe *regexp.Regexp = regexp.MustCompile(
-	`Found \d+ data race\(s\)`)
-
 func MustAtoi(digits string) int {
 	ret, err := strconv.Atoi(digits)
 	if err != nil {
@@ -335,229 +375,44 @@ func MustAtoi(digits string) int {
 	return ret
 }
 
-func parseFailures(ctx context.Context, logContents *json.Decoder) (*Output, error) {
-	ret := NewTestSummary()
-	allTestLogs := make(map[FQTest][]string)
-
-	// We parse log lines one at a time, but the "expected" and "actual" values are on
-	// separate log lines. Keep a buffer of any "expected" log lines missing a partner "actual".
-	halfAssertionFailure := make(map[FQTest]*AssertionFailure)
-	for logContents.More() {
-		doc := TestLogLine{}
-		err := logContents.Decode(&doc)
-		if err != nil {
-			return ret, err
-		}
-		doc.Output = trimRightSpace(doc.Output)
-
-		if doc.Action == "fail" {
-			if util.GDebug {
-				fmt.Printf("Found doc.Action=`fail`.\n  Doc:%+v\n", doc)
-			}
-			// All failures are associated with a `Package`. Some (most) failures also are
-			// associated with a `Test`. Exceptions include hangs/timeouts.
-			switch doc.Test {
-			case "":
-				ret.PackageFailures = append(ret.PackageFailures, doc)
-			default:
-				// We expect test failures to be accompanied with `output` test log lines. But we
-				// double-track them here as the definitive source of truth on whether a test
-				// failed.
-				ret.TestFailures = append(ret.TestFailures, doc.ToFQTest())
-			}
-			continue
-		}
-
-		if doc.Action != "output" {
-			continue
-		}
-		allTestLogs[doc.ToFQTest()] = append(allTestLogs[doc.ToFQTest()], doc.Output)
-
-		if matches := expectedRe.FindStringSubmatch(doc.Output); len(matches) > 0 {
-			if strings.Contains(doc.Test, "TestSabertooth") {
-				continue
-			}
-			if util.GDebug {
-				fmt.Printf("Found `expected`: %v\n  Adding half-assertion for: `%v`\n",
-					strings.TrimSpace(doc.Output),
-					doc.ToFQTest())
-				fmt.Printf("  %+v\n", doc)
-			}
-			if _, exists := halfAssertionFailure[doc.ToFQTest()]; exists {
-				panic(fmt.Sprintf("Half assertion already existed: %v", doc.ToFQTest()))
-			}
-
-			halfAssertionFailure[doc.ToFQTest()] = &AssertionFailure{
-				Package:  doc.Package,
-				File:     matches[1],
-				Line:     MustAtoi(matches[2]),
-				Expected: matches[3],
-			}
-			continue
-		}
-
-		if matches := actualRe.FindStringSubmatch(doc.Output); len(matches) > 0 {
-			if util.GDebug {
-				fmt.Printf("Found `actual`: %v\n  Adding Assertion for: `%v`\n", doc.Output, doc.ToFQTest())
-			}
-			failure := halfAssertionFailure[doc.ToFQTest()]
-			failure.Actual = matches[1]
-			ret.Assertions[doc.ToFQTest()] = append(ret.Assertions[doc.ToFQTest()], *failure)
-			ret.TestFailures = append(ret.TestFailures, doc.ToFQTest())
-			delete(halfAssertionFailure, doc.ToFQTest())
-			continue
-		}
-
-		// timeout stack traces can interleave with output from different tests. Keep a buffer for
-		// all remaining log lines for the test.
-		if startTimeoutRe.MatchString(doc.Output) {
-			if util.GDebug {
-				fmt.Println("Found timeout:", doc.Output)
-			}
-			ret.Timeouts[doc.ToFQTest()] = &TimeoutFailure{
-				LogLines: []string{doc.Output},
-			}
-			ret.TestFailures = append(ret.TestFailures, doc.ToFQTest())
-			continue
-		}
-
-		if timeoutFailure, exists := ret.Timeouts[doc.ToFQTest()]; exists {
-			timeoutFailure.LogLines = append(timeoutFailure.LogLines, doc.Output)
-			continue
-		}
-
-		if doc.Output == "WARNING: DATA RACE" {
-			if util.GDebug {
-				fmt.Println("Found data race. Package:", doc.Package, " FQTest:", doc.ToFQTest())
-				fmt.Println(doc.Output)
-			}
-			ret.Dataraces[doc.ToFQTest()] = &DataraceFailure{
-				Package:  doc.Package,
-				LogLines: []string{doc.Output},
-			}
-			ret.TestFailures = append(ret.TestFailures, doc.ToFQTest())
-			continue
-		}
-
-		if strings.HasPrefix(doc.Output, "panic: runtime error:") {
-			if util.GDebug {
-				fmt.Println("Found runtime error. Package:", doc.Package, " FQTest:", doc.ToFQTest())
-				fmt.Println(doc.Output)
-			}
-			ret.RuntimeErrors[doc.ToFQTest()] = &RuntimeFailure{
-				Package:  doc.Package,
-				LogLines: []string{doc.Output},
-			}
-			ret.TestFailures = append(ret.TestFailures, doc.ToFQTest())
-			continue
-		}
-
-		if dataraceFailure, exists := ret.Dataraces[FQTest(doc.Package)]; exists {
-			dataraceFailure.LogLines = append(dataraceFailure.LogLines, doc.Output)
-			continue
-		}
-
-		if runtimeError, exists := ret.RuntimeErrors[FQTest(doc.Package)]; exists {
-			runtimeError.LogLines = append(runtimeError.LogLines, doc.Output)
-		}
-	}
-
-	for test, expectedMsg := range halfAssertionFailure {
-		if util.GDebug && !strings.Contains(string(test), "TestSabertooth") {
-			fmt.Printf("Adding half assertion to full. Test: %v ExpectedMsg: %+v\n", test, *expectedMsg)
-		}
-		ret.Assertions[test] = append(ret.Assertions[test], *expectedMsg)
-	}
-
-	for test := range ret.Assertions {
-		if util.GDebug && !strings.Contains(string(test), "TestSabertooth") {
-			fmt.Println("Saving logs for assertion failure:", test)
-		}
-		ret.Logs[test] = allTestLogs[test]
-	}
-	for test := range ret.Timeouts {
-		if util.GDebug {
-			fmt.Println("Saving logs for timeout failure:", test)
-		}
-		ret.Logs[test] = allTestLogs[test]
-	}
-	for test := range ret.Dataraces {
-		if util.GDebug {
-			fmt.Println("Saving logs for datarace failure:", test)
-		}
-		ret.Logs[test] = allTestLogs[test]
+// Parses the first file of a zipped test log artifact.
+func parseArchive(ctx context.Context, zipped io.ReaderAt, size int64) (*Output, error) {
+	archive, err := zip.NewReader(zipped, size)
+	if err != nil {
+		return nil, err
 	}
-	for test := range ret.RuntimeErrors {
-		if util.GDebug {
-			fmt.Println("Saving logs for runtime error failure:", test)
-		}
-		ret.Logs[test] = allTestLogs[test]
+	if len(archive.File) == 0 {
+		return nil, fmt.Errorf("Archive has no files.")
 	}
 
-	if util.GDebug {
-		fmt.Println("All failures:", ret.TestFailures)
-		for _, testFailure := range ret.TestFailures {
-			_, aExists := ret.Assertions[testFailure]
-			_, tExists := ret.Timeouts[testFailure]
-			_, dExists := ret.Dataraces[testFailure]
-			_, rExists := ret.RuntimeErrors[testFailure]
-			if !aExists && !tExists && !dExists && !rExists {
-				fmt.Sprintf("Unknown test failure: %v", testFailure)
-			}
-		}
+	testLogFile := archive.File[0]
+	logContents, err := testLogFile.Open()
+	if err != nil {
+		return nil, err
 	}
+	defer logContents.Close()
 
-	ret.TestFailures = sortDedupTestFailures(ret.TestFailures)
-
-	return ret, nil
+	return parseTestResults(ctx, testLogFile.Name, logContents)
 }
 
-func sortDedupTestFailures(failures []FQTest) []FQTest {
-	ret := make([]FQTest, 0)
-	seen := make(map[FQTest]struct{})
-	for _, failure := range failures {
-		if _, exists := seen[failure]; exists {
-			continue
+// Parses a local test log. Either a zipped artifact as downloaded from github, or the log itself.
+func ParseTestLogFile(ctx context.Context, path string) (*Output, error) {
+	if strings.HasSuffix(path, ".zip") {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
 		}
 
-		seen[failure] = struct{}{}
-		ret = append(ret, failure)
+		return parseArchive(ctx, bytes.NewReader(contents), int64(len(contents)))
 	}
 
-	sort.Slice(ret, func(left, right int) bool {
-		return ret[left] < ret[right]
-	})
-
-	return ret
-}
-
-type TestLogLine struct {
-	Time string
-	// One of `fail` or `output`
-	Action  string
-	Package string
-	Output  string
-	Test    string
-	Elapsed float64
-}
-
-func (testLogLine TestLogLine) ToPackageFailureString() string {
-	return fmt.Sprintf("%v (%vs)", testLogLine.Package, testLogLine.Elapsed)
-}
-
-type FQTest string
-
-func (testLogLine TestLogLine) ToDataraceNamespace() FQTest {
-	return FQTest(testLogLine.Package)
-}
-
-func (testLogLine TestLogLine) ToFQTest() FQTest {
-	switch testLogLine.Test {
-	case "":
-		return FQTest(testLogLine.Package)
-	default:
-		return FQTest(fmt.Sprintf("%v.%v", testLogLine.Package, testLogLine.Test))
+	logFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer logFile.Close()
+
+	return parseTestResults(ctx, path, logFile)
 }
 
 func fetchAndParseFailures(ctx context.Context, client *github.Client, zippedLogArtifact *github.Artifact) (*Output, error) {
@@ -584,22 +439,7 @@ func fetchAndParseFailures(ctx context.Context, client *github.Client, zippedLog
 		os.WriteFile("gotest_logs.json.zip", zippedBytes.Bytes(), 0644)
 	}
 
-	archive, err := zip.NewReader(bytes.NewReader(zippedBytes.Bytes()), nCopied)
-	if err != nil {
-		return nil, err
-	}
-	if len(archive.File) == 0 {
-		return nil, fmt.Errorf("Archive has no files.")
-	}
-
-	testLogFile := archive.File[0]
-	logContents, err := testLogFile.Open()
-	if err != nil {
-		return nil, err
-	}
-	defer logContents.Close()
-
-	return parseFailures(ctx, json.NewDecoder(logContents))
+	return parseArchive(ctx, bytes.NewReader(zippedBytes.Bytes()), nCopied)
 
 	// ret := []TestLogLine{}
 	// // Example log lines to capture:
@@ -636,12 +476,23 @@ func (failure Failure) GetRepo() string {
 	return failure.WorkflowRun.GetRepository().GetName()
 }
 
-func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo string, runId, jobId int64) ([]Failure, error) {
+// Returns the output of the run's test jobs that have failures. With `includeSuccess`, jobs that
+// succeeded are also analyzed, for tests that flaked. See `FindFailingRuns`.
+func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo string, runId, jobId int64, includeSuccess bool) ([]Failure, error) {
+	return githubRunToTestOutputs(ctx, client, repo, runId, jobId, includeSuccess, false)
+}
+
+// Like `GithubRunToFailedTests`, but also returns the output of test jobs that passed. E.g: for
+// the skipped test inventory.
+func GithubRunToTestOutputs(ctx context.Context, client *github.Client, repo string, runId, jobId int64) ([]Failure, error) {
+	return githubRunToTestOutputs(ctx, client, repo, runId, jobId, true, true)
+}
+
+func githubRunToTestOutputs(ctx context.Context, client *github.Client, repo string, runId, jobId int64, includeSuccess, includePassing bool) ([]Failure, error) {
 	service := client.Actions
 	workflowRun, response, err := service.GetWorkflowRunByID(ctx, "viamrobotics", repo, runId)
+	lastResponse = response
 	if err != nil {
-		fmt.Println("Response:", util.ResponseBody(response.Body))
-		panic(err)
 		return nil, err
 	}
 
@@ -665,12 +516,16 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		arm     int64
 		goutils int64
 		app     int64
+		web     int64
 	}
-	var errors struct {
+	// The jobs of interest. Jobs that succeeded are only of interest with `includeSuccess`. Their
+	// output is only returned with `includePassing` or if it has flakes.
+	var testJobs struct {
 		amd     bool
 		arm     bool
 		goutils bool
 		app     bool
+		web     bool
 	}
 	var gitHash string
 
@@ -681,7 +536,6 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		if util.GDebug {
 			fmt.Printf("Job: %v Repo: %v Conclusion: %v\n", job.GetName(), repo, job.GetConclusion())
 		}
-
 		if repo == "rdk" && !strings.Contains(job.GetName(), "Go Unit Test") && !strings.Contains(job.GetName(), "Go Coverage Test") {
 			if util.GDebug {
 				fmt.Println(" Skipping because rdk and not test job.")
@@ -696,21 +550,26 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 			continue
 		}
 
-		if repo == "app" && !strings.Contains(job.GetName(), "test-go / Test Go") {
+		// Frontend tests write a Jest/Vitest `--json` report. E.g: `test-web / Test Web`.
+		if repo == "app" && !strings.Contains(job.GetName(), "test-go / Test Go") &&
+			!strings.Contains(job.GetName(), "test-web / Test Web") {
 			if util.GDebug {
 				fmt.Println(" Skipping because app and not test job.")
 			}
 			continue
 		}
 
-		if job.GetConclusion() != "failure" {
+		// A job that succeeded can still have tests that failed and passed on a rerun.
+		if job.GetConclusion() != "failure" && !(includeSuccess && job.GetConclusion() == "success") {
 			if util.GDebug {
 				fmt.Println(" Skipping because not failure.")
 			}
 			continue
 		}
 		if jobId != 0 && job.GetID() != jobId {
-			fmt.Printf("  Skipping because not jobid. Asked: %v Received: %v\n", jobId, job.GetID())
+			if util.GDebug {
+				fmt.Printf("  Skipping because not jobid. Asked: %v Received: %v\n", jobId, job.GetID())
+			}
 			continue
 		}
 
@@ -720,27 +579,30 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		case false && repo == "rdk" && strings.Contains(job.GetName(), "amd64"):
 			// JobName e.g: test / Go Unit Tests (buildjet-8vcpu-ubuntu-2204, ghcr.io/viamrobotics/rdk-devenv:amd64-cache, linux/amd...
 			jobIds.amd = job.GetID()
-			errors.amd = true
+			testJobs.amd = true
 		case false && repo == "rdk" && strings.Contains(job.GetName(), "location_de_arm"):
 			// JobName e.g: test / Go Unit Tests (buildjet-8vcpu-ubuntu-2204-arm, buildjet-pinned-location_de_arm, ghcr.io/viamrobot...
 			jobIds.arm = job.GetID()
-			errors.arm = true
+			testJobs.arm = true
 
 		// New RDK matchers
 		case repo == "rdk" && strings.Contains(job.GetName(), "linux-amd64"):
 			// JobName e.g: test / linux-amd64 Go Unit Tests
 			jobIds.amd = job.GetID()
-			errors.amd = true
+			testJobs.amd = true
 		case repo == "rdk" && strings.Contains(job.GetName(), "linux-arm64"):
 			// JobName e.g: test / linux-arm64 Go Unit Tests
 			jobIds.arm = job.GetID()
-			errors.arm = true
+			testJobs.arm = true
 		case repo == "goutils":
 			jobIds.goutils = job.GetID()
-			errors.goutils = true
+			testJobs.goutils = true
+		case repo == "app" && strings.Contains(job.GetName(), "test-web"):
+			jobIds.web = job.GetID()
+			testJobs.web = true
 		case repo == "app":
 			jobIds.app = job.GetID()
-			errors.app = true
+			testJobs.app = true
 		}
 	}
 
@@ -757,9 +619,14 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		arm     *github.Artifact
 		goutils *github.Artifact
 		app     *github.Artifact
+		web     *github.Artifact
 	}
+	// E.g: `junit-e2e.xml`. Analyzed the same as the test2json logs.
+	junitLogs := make([]*github.Artifact, 0)
 	for _, artifact := range artifacts.Artifacts {
 		switch {
+		case strings.HasSuffix(artifact.GetName(), ".xml"):
+			junitLogs = append(junitLogs, artifact)
 		case repo == "rdk" && artifact.GetName() == "test-linux-amd64.json":
 			logs.amd = artifact
 		case repo == "rdk" && artifact.GetName() == "test-linux-arm64.json":
@@ -768,16 +635,18 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 			logs.goutils = artifact
 		case repo == "app" && artifact.GetName() == "test.json":
 			logs.app = artifact
+		case repo == "app" && artifact.GetName() == "test-web.json":
+			logs.web = artifact
 		}
 	}
 
 	if util.GDebug {
 		fmt.Printf("NumArtifacts: %v Logs: %+v\n", len(artifacts.Artifacts), logs)
-		fmt.Printf("Errors: %+v\n", errors)
+		fmt.Printf("Test jobs: %+v\n", testJobs)
 	}
 
 	ret := []Failure{}
-	if errors.amd == true && logs.amd != nil {
+	if testJobs.amd == true && logs.amd != nil {
 		if util.GDebug {
 			fmt.Println("RDK Amd failures")
 		}
@@ -786,14 +655,14 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		if err != nil {
 			return nil, err
 		}
-		if !output.IsSuccess() {
+		if includePassing || !output.IsSuccess() || len(output.Flakes) > 0 {
 			jobLink := fmt.Sprintf("https://github.com/viamrobotics/%v/actions/runs/%v/job/%v", repo, runId, jobIds.amd)
 			ret = append(ret, Failure{"amd64", jobLink, gitHash, output, workflowRun})
 		}
 		ind.Close()
 	}
 
-	if errors.arm == true && logs.arm != nil {
+	if testJobs.arm == true && logs.arm != nil {
 		if util.GDebug {
 			fmt.Println("\nRDK Arm failures")
 		}
@@ -802,7 +671,7 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		if err != nil {
 			return nil, err
 		}
-		if !output.IsSuccess() {
+		if includePassing || !output.IsSuccess() || len(output.Flakes) > 0 {
 			// See above
 			jobLink := fmt.Sprintf("https://github.com/viamrobotics/%v/actions/runs/%v/job/%v", repo, runId, jobIds.arm)
 			ret = append(ret, Failure{"arm64", jobLink, gitHash, output, workflowRun})
@@ -810,7 +679,7 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		ind.Close()
 	}
 
-	if errors.goutils == true && logs.goutils != nil {
+	if testJobs.goutils == true && logs.goutils != nil {
 		if util.GDebug {
 			fmt.Println("\nGoutils failures")
 		}
@@ -819,7 +688,7 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		if err != nil {
 			return nil, err
 		}
-		if !output.IsSuccess() {
+		if includePassing || !output.IsSuccess() || len(output.Flakes) > 0 {
 			// See above
 			jobLink := fmt.Sprintf("https://github.com/viamrobotics/%v/actions/runs/%v/job/%v", repo, runId, jobIds.goutils)
 			ret = append(ret, Failure{"goutils", jobLink, gitHash, output, workflowRun})
@@ -827,7 +696,7 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		ind.Close()
 	}
 
-	if errors.app == true && logs.app != nil {
+	if testJobs.app == true && logs.app != nil {
 		if util.GDebug {
 			fmt.Println("\nApp failures")
 		}
@@ -836,7 +705,7 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		if err != nil {
 			return nil, err
 		}
-		if !output.IsSuccess() {
+		if includePassing || !output.IsSuccess() || len(output.Flakes) > 0 {
 			// See above
 			jobLink := fmt.Sprintf("https://github.com/viamrobotics/%v/actions/runs/%v/job/%v", repo, runId, jobIds.app)
 			ret = append(ret, Failure{"app", jobLink, gitHash, output, workflowRun})
@@ -844,17 +713,49 @@ func GithubRunToFailedTests(ctx context.Context, client *github.Client, repo str
 		ind.Close()
 	}
 
+	if testJobs.web == true && logs.web != nil {
+		if util.GDebug {
+			fmt.Println("\nApp web failures")
+		}
+		ind := NewIndenter()
+		output, err := fetchAndParseFailures(ctx, client, logs.web)
+		if err != nil {
+			return nil, err
+		}
+		if includePassing || !output.IsSuccess() || len(output.Flakes) > 0 {
+			// See above
+			jobLink := fmt.Sprintf("https://github.com/viamrobotics/%v/actions/runs/%v/job/%v", repo, runId, jobIds.web)
+			ret = append(ret, Failure{"web", jobLink, gitHash, output, workflowRun})
+		}
+		ind.Close()
+	}
+
+	// Github does not record which job uploaded an artifact. JUnit failures link to the run, and
+	// are left out when analyzing a single job.
+	for _, artifact := range junitLogs {
+		if jobId != 0 {
+			break
+		}
+		if util.GDebug {
+			fmt.Println("\nJUnit failures:", artifact.GetName())
+		}
+		ind := NewIndenter()
+		output, err := fetchAndParseFailures(ctx, client, artifact)
+		if err != nil {
+			return nil, err
+		}
+		if includePassing || !output.IsSuccess() || len(output.Flakes) > 0 {
+			runLink := fmt.Sprintf("https://github.com/viamrobotics/%v/actions/runs/%v", repo, runId)
+			variant := strings.TrimSuffix(artifact.GetName(), ".xml")
+			ret = append(ret, Failure{variant, runLink, workflowRun.GetHeadSHA(), output, workflowRun})
+		}
+		ind.Close()
+	}
+
 	return ret, nil
 }
 
-func (server *BFServer) Start() {
-	// ctx := context.Background()
-	// client := github.NewTokenClient(ctx, githubToken)
-	// failures, err := GithubRunToFailedTests(ctx, client, 5717936462)
-	// fmt.Println("Rate:", lastResponse.Rate)
-	// if err != nil {
-	//  	panic(err)
-	// }
-	//
-	// fmt.Println("Failures:", failures)
+// Serves the API on the address. E.g: `:8080`. See `ServeHTTP`.
+func (server *BFServer) Start(addr string) error {
+	return http.ListenAndServe(addr, server)
 }
@@ -3,25 +3,31 @@ package service
 import (
 	"archive/zip"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"testing"
 
 	"github.com/google/go-github/v61/github"
+	"github.com/viamrobotics/bfserver/parser/parsertest"
 )
 
 var githubToken string
 
+// The jira credentials, for tests that talk to jira.
+var jiraUsername, jiraToken string
+
 func init() {
 	githubToken = os.Getenv("github_token")
+	jiraUsername = os.Getenv("jira_username")
+	jiraToken = os.Getenv("jira_api_token")
 }
 
 func TestGetFailingTestsForRun(t *testing.T) {
 	t.Skip()
 	ctx := context.Background()
 	client := github.NewTokenClient(ctx, githubToken)
-	failures, err := GithubRunToFailedTests(ctx, client, 5717936462, 0)
+	failures, err := GithubRunToFailedTests(ctx, client, "rdk", 5717936462, 0, false)
 	fmt.Println("Rate:", lastResponse.Rate)
 	if err != nil {
 		panic(err)
@@ -35,7 +41,7 @@ func TestFindFailingRuns(t *testing.T) {
 	ctx := context.Background()
 	client := github.NewTokenClient(ctx, githubToken)
 	// 7 total runs -- 2 failures
-	failedRuns, err := FindFailingRuns(ctx, client, "2023-08-01", "2023-08-02")
+	failedRuns, err := FindFailingRuns(ctx, client, "2023-08-01", "2023-08-02", false)
 	fmt.Println("Rate:", lastResponse.Rate)
 	if err != nil {
 		panic(err)
@@ -45,12 +51,15 @@ func TestFindFailingRuns(t *testing.T) {
 }
 
 func TestRunReport(t *testing.T) {
+	if githubToken == "" {
+		t.Skip("No github_token")
+	}
 	ctx := context.Background()
 	client := github.NewTokenClient(ctx, githubToken)
 	// 7 total runs -- 2 failures
-	// failedRuns, err := FindFailingRuns(ctx, client, "2023-08-01", "2023-08-02")
+	// failedRuns, err := FindFailingRuns(ctx, client, "2023-08-01", "2023-08-02", false)
 
-	failedRuns, err := FindFailingRuns(ctx, client, "2023-08-07", "2023-08-08")
+	failedRuns, err := FindFailingRuns(ctx, client, "2023-08-07", "2023-08-08", false)
 	fmt.Println("Rate:", lastResponse.Rate)
 	if err != nil {
 		panic(err)
@@ -58,7 +67,7 @@ func TestRunReport(t *testing.T) {
 
 	for _, failedRun := range failedRuns {
 		// Get logs for run and parse failures
-		testFailures, err := GithubRunToFailedTests(ctx, client, failedRun.GetID(), 0)
+		testFailures, err := GithubRunToFailedTests(ctx, client, failedRun.GetRepository().GetName(), failedRun.GetID(), 0, false)
 		if err != nil {
 			fmt.Println("Err:", err)
 			continue
@@ -85,7 +94,7 @@ func TestRunReport(t *testing.T) {
 	fmt.Println("Rate:", lastResponse.Rate)
 }
 
-func zipFileToJsonDecoder(reader *zip.ReadCloser) *json.Decoder {
+func zipFileToReader(reader *zip.ReadCloser) io.Reader {
 	// _foo_.zip file will contain a single log file.
 	if cnt := len(reader.File); cnt != 1 {
 		panic(fmt.Sprintf("Too many files. Cnt: %v", cnt))
@@ -98,29 +107,63 @@ func zipFileToJsonDecoder(reader *zip.ReadCloser) *json.Decoder {
 	}
 	// Dan: I do not believe the `fileReader` needs to be closed.
 
-	return json.NewDecoder(fileReader)
+	return fileReader
 }
 
-func TestCaptureContext(t *testing.T) {
-	t.Skip()
+func TestUnknownFailure(t *testing.T) {
+	const pkg = "go.viam.com/rdk/foo"
+	output, err := parseFailures(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "output", Package: pkg, Test: "TestFoo", Output: "=== RUN   TestFoo\n"},
+		TestLogLine{Action: "output", Package: pkg, Test: "TestFoo", Output: "    foo_test.go:12: something went wrong\n"},
+		TestLogLine{Action: "output", Package: pkg, Test: "TestFoo", Output: "--- FAIL: TestFoo (0.00s)\n"},
+		TestLogLine{Action: "fail", Package: pkg, Test: "TestFoo"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	var outputs *Output
-	var contextTestFile *zip.ReadCloser
-	var err error
-	ctx := context.Background()
+	const fqTest = FQTest(pkg + ".TestFoo")
+	unknownFailure, exists := output.UnknownFailures[fqTest]
+	if !exists {
+		t.Fatalf("Expected an unknown failure for %v. Output: %+v", fqTest, output)
+	}
+	if cnt := len(unknownFailure.LogLines); cnt != 3 {
+		t.Fatalf("Wrong number of log lines: %v", cnt)
+	}
 
-	for _, filename := range []string{
-		"./testdata/failure_context_test_logs.json.zip",
-		"./testdata/timeout_context_test_logs.json.zip",
-		"./testdata/datarace_context_test_logs.json.zip",
-	} {
-		contextTestFile, err = zip.OpenReader(filename)
-		if err != nil {
-			panic(err)
-		}
-		defer contextTestFile.Close()
+	summary, err := GetSummaryForFailure(Failure{Output: output}, fqTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Test Unclassified Failure: " + string(fqTest); summary != expected {
+		t.Fatalf("Wrong summary. Expected: `%v` Actual: `%v`", expected, summary)
+	}
+
+	tickets := CreateTicketObjectsFromFailure(Failure{Output: output})
+	if len(tickets) != 1 || tickets[0].Issue.Fields.Summary != summary {
+		t.Fatalf("Wrong tickets: %+v", tickets)
+	}
+}
+
+func TestJSFailureSummary(t *testing.T) {
+	fqTest := FQTest("web/src/login.test.ts.Login/renders_the_form")
+	output := NewTestSummary()
+	output.JSFailures[fqTest] = &JSFailure{
+		Package: "web/src/login.test.ts",
+		Test:    "Login/renders_the_form",
+		File:    "web/src/login.test.ts",
+		Line:    12,
+		Message: "expect(received).toBe(expected)",
+	}
+	output.TestFailures = []FQTest{fqTest}
+
+	if link := GetJSCodeLink(output.JSFailures[fqTest], "app", "abc123"); link !=
+		"https://github.com/viamrobotics/app/blob/abc123/web/src/login.test.ts#L12" {
+		t.Fatalf("Wrong code link: %v", link)
+	}
 
-		outputs, _ = parseFailures(ctx, zipFileToJsonDecoder(contextTestFile))
-		outputs.PrettyPrint("\t")
+	summary, err := GetSummaryForFailure(Failure{Output: output}, fqTest)
+	if err != nil || summary != "Test Failure: "+string(fqTest) {
+		t.Fatalf("Wrong summary: %v Err: %v", summary, err)
 	}
 }
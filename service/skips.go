@@ -0,0 +1,208 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/viamrobotics/bfserver/parser"
+	"github.com/viamrobotics/bfserver/util"
+)
+
+// E.g: "RSDK-1234"
+var ticketRefRe *regexp.Regexp = regexp.MustCompile(
+	`\b[A-Z][A-Z0-9]+-\d+\b`)
+
+// Returns the ticket keys the skip message references. E.g: `RSDK-1234`.
+func ticketRefs(reason string) []string {
+	return ticketRefRe.FindAllString(reason, -1)
+}
+
+// The skipped tests of a single repo and variant (e.g: `rdk` `amd64`) in a run. Inventories are
+// persisted between runs such that newly skipped tests can be flagged.
+type SkipInventory struct {
+	Repo    string
+	Variant string
+	RunId   int64
+	// Keyed by the skipped test, the skip message.
+	Skips map[FQTest]string
+}
+
+func NewSkipInventory(repo string, runId int64, failure Failure) *SkipInventory {
+	ret := &SkipInventory{
+		Repo:    repo,
+		Variant: failure.Variant,
+		RunId:   runId,
+		Skips:   make(map[FQTest]string),
+	}
+	for fqTest, skip := range failure.Output.Skips {
+		ret.Skips[fqTest] = skip.Reason
+	}
+
+	return ret
+}
+
+// E.g: `~/.config/bfserver/skips/rdk.amd64.json`.
+func SkipInventoryPath(configDir, repo, variant string) string {
+	return filepath.Join(configDir, "bfserver", "skips", fmt.Sprintf("%v.%v.json", repo, variant))
+}
+
+// Returns nil, without an error, if no inventory was saved yet.
+func LoadSkipInventory(path string) (*SkipInventory, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var ret SkipInventory
+	if err := json.Unmarshal(contents, &ret); err != nil {
+		return nil, fmt.Errorf("Bad skip inventory: `%v` Err: %w", path, err)
+	}
+
+	return &ret, nil
+}
+
+func (inventory *SkipInventory) Save(path string) error {
+	contents, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, contents, 0644)
+}
+
+// Returns all ticket keys referenced by skip messages, sorted.
+func (inventory *SkipInventory) TicketRefs() []string {
+	refs := make(util.Set)
+	for _, reason := range inventory.Skips {
+		for _, ref := range ticketRefs(reason) {
+			refs[ref] = struct{}{}
+		}
+	}
+
+	ret := make([]string, 0, len(refs))
+	for ref := range refs {
+		ret = append(ret, ref)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+// How the skipped tests changed between two runs.
+type SkipDrift struct {
+	// False if there was no previous inventory to compare against.
+	HasPrevious bool
+	// People quietly `t.Skip` flakes. These deserve a look.
+	NewlySkipped    []FQTest
+	NoLongerSkipped []FQTest
+	// Skipped tests whose skip message references a ticket that is closed. Keyed by the skipped
+	// test, the closed ticket keys.
+	ClosedTicketRefs map[FQTest][]string
+}
+
+// Compares the skipped tests of two runs of the same repo and variant. A nil `previous` means
+// there is nothing to compare against.
+func DiffSkipInventories(previous, current *SkipInventory) *SkipDrift {
+	ret := &SkipDrift{
+		HasPrevious:      previous != nil,
+		NewlySkipped:     make([]FQTest, 0),
+		NoLongerSkipped:  make([]FQTest, 0),
+		ClosedTicketRefs: make(map[FQTest][]string),
+	}
+	if previous == nil {
+		return ret
+	}
+
+	for fqTest := range current.Skips {
+		if _, exists := previous.Skips[fqTest]; !exists {
+			ret.NewlySkipped = append(ret.NewlySkipped, fqTest)
+		}
+	}
+	for fqTest := range previous.Skips {
+		if _, exists := current.Skips[fqTest]; !exists {
+			ret.NoLongerSkipped = append(ret.NoLongerSkipped, fqTest)
+		}
+	}
+	ret.NewlySkipped = parser.SortDedupTestFailures(ret.NewlySkipped)
+	ret.NoLongerSkipped = parser.SortDedupTestFailures(ret.NoLongerSkipped)
+
+	return ret
+}
+
+// Records the skipped tests that reference one of the `closedTickets`.
+func (drift *SkipDrift) AddClosedTicketRefs(current *SkipInventory, closedTickets util.Set) {
+	for fqTest, reason := range current.Skips {
+		for _, ref := range ticketRefs(reason) {
+			if closedTickets.Contains(ref) {
+				drift.ClosedTicketRefs[fqTest] = append(drift.ClosedTicketRefs[fqTest], ref)
+			}
+		}
+	}
+}
+
+func (drift *SkipDrift) PrettyPrint(indent string, current *SkipInventory) {
+	if !drift.HasPrevious {
+		fmt.Printf("%sNo previous inventory. Skipped tests: %d\n", indent, len(current.Skips))
+	}
+
+	for _, fqTest := range drift.NewlySkipped {
+		fmt.Printf("%sNewly skipped: %v Reason: %v\n", indent, fqTest, current.Skips[fqTest])
+	}
+
+	for _, fqTest := range drift.NoLongerSkipped {
+		fmt.Printf("%sNo longer skipped: %v\n", indent, fqTest)
+	}
+
+	closed := make([]FQTest, 0, len(drift.ClosedTicketRefs))
+	for fqTest := range drift.ClosedTicketRefs {
+		closed = append(closed, fqTest)
+	}
+	for _, fqTest := range parser.SortDedupTestFailures(closed) {
+		fmt.Printf("%sSkipped for closed ticket: %v Tickets: %v Reason: %v\n",
+			indent, fqTest, strings.Join(drift.ClosedTicketRefs[fqTest], ", "), current.Skips[fqTest])
+	}
+}
+
+// Returns the subset of the ticket keys that jira shows as closed. Keys that do not exist are
+// ignored.
+func GetClosedTickets(jiraUsername, jiraToken string, keys []string) (util.Set, error) {
+	ret := make(util.Set)
+	if len(keys) == 0 {
+		return ret, nil
+	}
+
+	tp := jira.BasicAuthTransport{
+		Username: jiraUsername,
+		Password: jiraToken,
+	}
+	jiraClient, _ := jira.NewClient(tp.Client(), "https://viam.atlassian.net/")
+
+	jql := fmt.Sprintf("key in (%v) AND statusCategory = Done", strings.Join(keys, ", "))
+	issues, _, err := jiraClient.Issue.Search(jql, &jira.SearchOptions{
+		MaxResults: 1000,
+		// A skip message can reference a key that does not exist. E.g: `UTF-8`.
+		ValidateQuery: "warn",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		ret[issue.Key] = struct{}{}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+	"github.com/viamrobotics/bfserver/util"
+)
+
+func TestSkipInventory(t *testing.T) {
+	const pkg = "go.viam.com/rdk/services/motion/builtin"
+	output := func(test, line string) TestLogLine {
+		return TestLogLine{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+
+	logs, err := parseFailures(context.Background(), parsertest.LogReader(
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnGlobe"},
+		output("TestMoveOnGlobe", "=== RUN   TestMoveOnGlobe"),
+		output("TestMoveOnGlobe", "    motion_test.go:42: flaky, see RSDK-1234"),
+		output("TestMoveOnGlobe", "--- SKIP: TestMoveOnGlobe (0.00s)"),
+		TestLogLine{Action: "skip", Package: pkg, Test: "TestMoveOnGlobe"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestMoveOnMap"},
+		TestLogLine{Action: "skip", Package: pkg, Test: "TestMoveOnMap"},
+		TestLogLine{Action: "run", Package: pkg, Test: "TestStop"},
+		TestLogLine{Action: "pass", Package: pkg, Test: "TestStop"},
+		TestLogLine{Action: "pass", Package: pkg},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !logs.IsSuccess() || len(logs.Skips) != 2 {
+		t.Fatalf("Expected two skips and no failures. Skips: %v", logs.Skips)
+	}
+	globe := FQTest(pkg + ".TestMoveOnGlobe")
+	if reason := logs.Skips[globe].Reason; reason != "flaky, see RSDK-1234" {
+		t.Fatalf("Wrong skip reason: %v", reason)
+	}
+
+	current := NewSkipInventory("rdk", 2, Failure{Variant: "amd64", Output: logs})
+	path := SkipInventoryPath(t.TempDir(), "rdk", "amd64")
+	if filepath.Base(path) != "rdk.amd64.json" {
+		t.Fatalf("Wrong inventory path: %v", path)
+	}
+
+	previous, err := LoadSkipInventory(path)
+	if err != nil || previous != nil {
+		t.Fatalf("Expected no previous inventory. Inventory: %v Err: %v", previous, err)
+	}
+	if drift := DiffSkipInventories(previous, current); drift.HasPrevious || len(drift.NewlySkipped) != 0 {
+		t.Fatalf("Expected no drift without a previous inventory: %+v", drift)
+	}
+
+	if err := (&SkipInventory{
+		Repo: "rdk", Variant: "amd64", RunId: 1,
+		Skips: map[FQTest]string{FQTest(pkg + ".TestMoveOnMap"): "", FQTest(pkg + ".TestStop"): ""},
+	}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	previous, err = LoadSkipInventory(path)
+	if err != nil || previous == nil || previous.RunId != 1 {
+		t.Fatalf("Failed to load the saved inventory: %v Err: %v", previous, err)
+	}
+
+	drift := DiffSkipInventories(previous, current)
+	if len(drift.NewlySkipped) != 1 || drift.NewlySkipped[0] != globe {
+		t.Fatalf("Wrong newly skipped tests: %v", drift.NewlySkipped)
+	}
+	if len(drift.NoLongerSkipped) != 1 || drift.NoLongerSkipped[0] != FQTest(pkg+".TestStop") {
+		t.Fatalf("Wrong no longer skipped tests: %v", drift.NoLongerSkipped)
+	}
+
+	if refs := current.TicketRefs(); len(refs) != 1 || refs[0] != "RSDK-1234" {
+		t.Fatalf("Wrong ticket refs: %v", refs)
+	}
+	drift.AddClosedTicketRefs(current, util.NewSet([]string{"RSDK-1234"}))
+	if refs := drift.ClosedTicketRefs[globe]; len(refs) != 1 {
+		t.Fatalf("Expected the closed ticket to be flagged: %v", drift.ClosedTicketRefs)
+	}
+}
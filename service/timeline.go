@@ -0,0 +1,290 @@
+package service
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+)
+
+// A Chrome trace event. See the "Trace Event Format" document. Traces open in
+// https://ui.perfetto.dev or chrome://tracing.
+type TraceEvent struct {
+	Name string `json:"name"`
+	Cat  string `json:"cat,omitempty"`
+	// The phase. `X` for a span, `i` for an instant and `M` for track names.
+	Ph string `json:"ph"`
+	// Microseconds since the start of the trace.
+	Ts  int64 `json:"ts"`
+	Dur int64 `json:"dur,omitempty"`
+	// A package is a process and each of its tracks is a thread.
+	Pid int `json:"pid"`
+	Tid int `json:"tid"`
+	// The scope of an instant. `t` marks the thread only.
+	S string `json:"s,omitempty"`
+	// A reserved color name. E.g: `terrible`.
+	Cname string         `json:"cname,omitempty"`
+	Args  map[string]any `json:"args,omitempty"`
+}
+
+type Trace struct {
+	TraceEvents     []TraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// The tracks of a package. Parallel tests (those that paused) get a track each, numbered after
+// these.
+const (
+	packageTrack = iota
+	serialTestTrack
+	firstParallelTestTrack
+)
+
+// Span colors. See the trace viewer's reserved color names.
+const (
+	failedColor     = "terrible"
+	pausedColor     = "grey"
+	unfinishedColor = "yellow"
+)
+
+// Returns the earliest and latest time in the test and package lifecycles.
+func (output *Output) timelineBounds() (time.Time, time.Time) {
+	var start, end time.Time
+	add := func(ts time.Time) {
+		if ts.IsZero() {
+			return
+		}
+		if start.IsZero() || ts.Before(start) {
+			start = ts
+		}
+		if ts.After(end) {
+			end = ts
+		}
+	}
+
+	for _, record := range output.Packages {
+		add(record.Start)
+		add(record.End)
+	}
+	for _, record := range output.Tests {
+		add(record.Start)
+		add(record.End)
+	}
+
+	return start, end
+}
+
+// Converts the test and package lifecycles into a trace of what ran when. Each package gets a
+// track for the package as a whole, a track for its serial tests and a track per parallel test.
+// Time spent paused is a child span of the test. Failures are colored and marked with an instant.
+// Tests that never finished (e.g: a timeout) end with the trace.
+func (output *Output) Timeline() *Trace {
+	ret := &Trace{TraceEvents: make([]TraceEvent, 0), DisplayTimeUnit: "ms"}
+	traceStart, traceEnd := output.timelineBounds()
+	if traceStart.IsZero() {
+		return ret
+	}
+	ts := func(at time.Time) int64 {
+		return at.Sub(traceStart).Microseconds()
+	}
+	span := func(name, cat string, pid, tid int, start, end time.Time) TraceEvent {
+		return TraceEvent{
+			Name: name, Cat: cat, Ph: "X", Ts: ts(start), Dur: end.Sub(start).Microseconds(),
+			Pid: pid, Tid: tid,
+		}
+	}
+	trackName := func(pid, tid int, name string) TraceEvent {
+		return TraceEvent{Name: "thread_name", Ph: "M", Pid: pid, Tid: tid, Args: map[string]any{"name": name}}
+	}
+
+	// Group the tests by package, in the order they started.
+	testsByPackage := make(map[string][]*TestRecord)
+	for _, record := range output.Tests {
+		if !record.Start.IsZero() {
+			testsByPackage[record.Package] = append(testsByPackage[record.Package], record)
+		}
+	}
+	packages := make([]string, 0)
+	for pkg := range output.Packages {
+		packages = append(packages, pkg)
+	}
+	for pkg := range testsByPackage {
+		if _, exists := output.Packages[pkg]; !exists {
+			packages = append(packages, pkg)
+		}
+	}
+	sort.Strings(packages)
+
+	for idx, pkg := range packages {
+		pid := idx + 1
+		ret.TraceEvents = append(ret.TraceEvents,
+			TraceEvent{Name: "process_name", Ph: "M", Pid: pid, Args: map[string]any{"name": pkg}},
+			trackName(pid, packageTrack, "package"),
+			trackName(pid, serialTestTrack, "tests"))
+
+		tests := testsByPackage[pkg]
+		sort.Slice(tests, func(left, right int) bool {
+			if !tests[left].Start.Equal(tests[right].Start) {
+				return tests[left].Start.Before(tests[right].Start)
+			}
+			return tests[left].Name < tests[right].Name
+		})
+
+		packageEnd := traceEnd
+		if record := output.Packages[pkg]; record != nil {
+			packageStart := record.Start
+			if packageStart.IsZero() && len(tests) > 0 {
+				// Go versions prior to 1.20 do not emit a `start` action.
+				packageStart = tests[0].Start
+			}
+			if !record.End.IsZero() {
+				packageEnd = record.End
+			}
+			if !packageStart.IsZero() {
+				event := span(pkg, "package", pid, packageTrack, packageStart, packageEnd)
+				event.Args = map[string]any{"status": record.Status}
+				if record.Status == "fail" {
+					event.Cname = failedColor
+				}
+				ret.TraceEvents = append(ret.TraceEvents, event)
+			}
+		}
+
+		// Non-parallel subtests run within their parent and share its track.
+		tracks := make(map[FQTest]int)
+		nextTrack := firstParallelTestTrack
+		for _, record := range tests {
+			tid := serialTestTrack
+			switch {
+			case len(record.Pauses) > 0:
+				tid = nextTrack
+				nextTrack++
+				ret.TraceEvents = append(ret.TraceEvents, trackName(pid, tid, record.Name))
+			case record.Parent != "":
+				if parentTrack, exists := tracks[record.Parent]; exists {
+					tid = parentTrack
+				}
+			}
+			tracks[record.ToFQTest()] = tid
+
+			end := record.End
+			if end.IsZero() {
+				end = packageEnd
+			}
+			event := span(record.Name, "test", pid, tid, record.Start, end)
+			event.Args = map[string]any{"status": record.Status, "elapsed": record.Elapsed}
+			switch {
+			case record.Status == "fail":
+				event.Cname = failedColor
+			case !record.IsFinished():
+				event.Cname = unfinishedColor
+			}
+			ret.TraceEvents = append(ret.TraceEvents, event)
+
+			for _, pause := range record.Pauses {
+				pauseEnd := pause.End
+				if pauseEnd.IsZero() {
+					pauseEnd = end
+				}
+				paused := span("paused", "pause", pid, tid, pause.Start, pauseEnd)
+				paused.Cname = pausedColor
+				ret.TraceEvents = append(ret.TraceEvents, paused)
+			}
+
+			if record.Status == "fail" {
+				ret.TraceEvents = append(ret.TraceEvents, TraceEvent{
+					Name: "FAIL " + record.Name, Cat: "failure", Ph: "i", Ts: ts(end),
+					Pid: pid, Tid: tid, S: "t",
+				})
+			}
+		}
+	}
+
+	return ret
+}
+
+func (trace *Trace) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(trace)
+}
+
+// A self-contained page that draws the trace's spans, one row per track. For when a trace viewer
+// is not at hand.
+var timelineHTML = template.Must(template.New("timeline").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Test timeline</title>
+<style>
+  body { font-family: sans-serif; font-size: 12px; }
+  .process { font-weight: bold; margin-top: 8px; }
+  .track { position: relative; height: 18px; border-bottom: 1px solid #eee; }
+  .label { position: absolute; left: 0; width: 240px; overflow: hidden; white-space: nowrap; }
+  .lane { position: absolute; left: 250px; right: 0; top: 0; bottom: 0; }
+  .span { position: absolute; top: 2px; height: 14px; background: #7aa6da; overflow: hidden;
+          white-space: nowrap; opacity: 0.85; }
+  .span.terrible { background: #d9534f; }
+  .span.grey { background: #bbb; }
+  .span.yellow { background: #f0ad4e; }
+</style>
+</head>
+<body>
+<div id="timeline"></div>
+<script>
+const trace = {{.}};
+const events = trace.traceEvents;
+let end = 1;
+for (const event of events) {
+  end = Math.max(end, event.ts + (event.dur || 0));
+}
+const processes = new Map();
+const trackNames = new Map();
+for (const event of events) {
+  if (event.ph !== "M") {
+    continue;
+  }
+  if (event.name === "process_name") {
+    processes.set(event.pid, event.args.name);
+  } else if (event.name === "thread_name") {
+    trackNames.set(event.pid + "/" + event.tid, event.args.name);
+  }
+}
+const root = document.getElementById("timeline");
+for (const [pid, name] of processes) {
+  const header = document.createElement("div");
+  header.className = "process";
+  header.textContent = name;
+  root.appendChild(header);
+  const tids = [...new Set(events.filter(e => e.pid === pid && e.ph === "X").map(e => e.tid))].sort((a, b) => a - b);
+  for (const tid of tids) {
+    const track = document.createElement("div");
+    track.className = "track";
+    const label = document.createElement("div");
+    label.className = "label";
+    label.textContent = trackNames.get(pid + "/" + tid) || tid;
+    const lane = document.createElement("div");
+    lane.className = "lane";
+    track.append(label, lane);
+    for (const event of events.filter(e => e.pid === pid && e.tid === tid && e.ph === "X")) {
+      const span = document.createElement("div");
+      span.className = "span " + (event.cname || "");
+      span.style.left = (100 * event.ts / end) + "%";
+      span.style.width = Math.max(0.1, 100 * event.dur / end) + "%";
+      span.textContent = event.name;
+      span.title = event.name + " " + (event.dur / 1000).toFixed(1) + "ms " + JSON.stringify(event.args || {});
+      lane.appendChild(span);
+    }
+    root.appendChild(track);
+  }
+}
+</script>
+</body>
+</html>
+`))
+
+// Writes a standalone HTML page of the trace.
+func (trace *Trace) WriteHTML(writer io.Writer) error {
+	return timelineHTML.Execute(writer, trace)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/viamrobotics/bfserver/parser/parsertest"
+)
+
+func TestTimeline(t *testing.T) {
+	const pkg = "go.viam.com/rdk/robot/impl"
+	at := func(secs int) string {
+		return "2023-08-01T20:15:" + []string{"00", "01", "02", "03", "04", "05", "06"}[secs] + "Z"
+	}
+	doc := func(secs int, action, test string) TestLogLine {
+		return TestLogLine{Time: at(secs), Action: action, Package: pkg, Test: test}
+	}
+
+	logs, err := parseFailures(context.Background(), parsertest.LogReader(
+		doc(0, "start", ""),
+		doc(0, "run", "TestSerial"),
+		doc(1, "run", "TestSerial/sub"),
+		doc(2, "pass", "TestSerial/sub"),
+		doc(2, "pass", "TestSerial"),
+		doc(2, "run", "TestParallelA"),
+		doc(2, "pause", "TestParallelA"),
+		doc(2, "run", "TestParallelB"),
+		doc(2, "pause", "TestParallelB"),
+		doc(3, "cont", "TestParallelA"),
+		doc(3, "cont", "TestParallelB"),
+		doc(5, "fail", "TestParallelA"),
+		doc(6, "pass", "TestParallelB"),
+		doc(6, "fail", ""),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trace := logs.Timeline()
+	spans := make(map[string]TraceEvent)
+	tracks := make(map[int]string)
+	var failureMarks []TraceEvent
+	for _, event := range trace.TraceEvents {
+		switch {
+		case event.Ph == "X" && event.Cat != "pause":
+			spans[event.Name] = event
+		case event.Ph == "M" && event.Name == "thread_name":
+			tracks[event.Tid] = event.Args["name"].(string)
+		case event.Ph == "i":
+			failureMarks = append(failureMarks, event)
+		}
+	}
+
+	if span := spans[pkg]; span.Tid != packageTrack || span.Dur != 6_000_000 || span.Cname != failedColor {
+		t.Fatalf("Wrong package span: %+v", span)
+	}
+	// The subtest is nested in its parent's track.
+	if spans["TestSerial"].Tid != serialTestTrack || spans["TestSerial/sub"].Tid != serialTestTrack ||
+		spans["TestSerial/sub"].Ts != 1_000_000 {
+		t.Fatalf("Wrong serial spans: %+v %+v", spans["TestSerial"], spans["TestSerial/sub"])
+	}
+	parallelA, parallelB := spans["TestParallelA"], spans["TestParallelB"]
+	if parallelA.Tid == parallelB.Tid || tracks[parallelA.Tid] != "TestParallelA" || tracks[parallelB.Tid] != "TestParallelB" {
+		t.Fatalf("Expected a track per parallel test. Tracks: %v", tracks)
+	}
+	if parallelA.Cname != failedColor || parallelA.Dur != 3_000_000 {
+		t.Fatalf("Wrong failed span: %+v", parallelA)
+	}
+	if len(failureMarks) != 1 || failureMarks[0].Ts != 5_000_000 || failureMarks[0].Tid != parallelA.Tid {
+		t.Fatalf("Wrong failure marks: %+v", failureMarks)
+	}
+
+	var encoded bytes.Buffer
+	if err := trace.WriteJSON(&encoded); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Trace
+	if err := json.Unmarshal(encoded.Bytes(), &decoded); err != nil || len(decoded.TraceEvents) != len(trace.TraceEvents) {
+		t.Fatalf("Bad trace JSON. Err: %v", err)
+	}
+
+	var page bytes.Buffer
+	if err := trace.WriteHTML(&page); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(page.String(), `"traceEvents":`) {
+		t.Fatalf("Expected the trace to be embedded in the page.")
+	}
+}
@@ -25,6 +25,15 @@ type Arg struct {
 	Dedup       bool
 	HandRun     bool
 	FileTickets bool
+	// Also analyze runs that succeeded, for tests that flaked. See `service.FindFailingRuns`.
+	Flakes bool
+	// Also write a standalone HTML timeline.
+	Html bool
+	// E.g: `--format markdown`. See `service.ReportFormats`. `--json` is short for `--format json`.
+	Format string
+	Json   bool
+	// Where to write the report. Stdout if empty.
+	OutputPath string
 
 	Url   string
 	RunId int64
@@ -128,7 +137,7 @@ func ParseProgramArgs() *Arg {
 		}
 	}
 
-	commands := NewSet([]string{"analyze", "discover", "list", "test"})
+	commands := NewSet([]string{"analyze", "discover", "list", "skips", "test", "timeline"})
 	// First pass -- find the command. `os.Args` starts with the binary, e.g: `./cli`.
 	for _, arg := range os.Args[1:] {
 		if commands.Contains(arg) {
@@ -148,8 +157,16 @@ func ParseProgramArgs() *Arg {
 		"debug":   &GDebug,
 		"d":       &GDebug,
 		"handRun": &ret.HandRun,
+		"html":    &ret.Html,
+		"json":    &ret.Json,
+		"flakes":  &ret.Flakes,
 		"file":    &ret.FileTickets}
-	for _, rawArg := range os.Args {
+	// Flags that take a value. E.g: `--format junit` or `--format=junit`.
+	valueFlags := map[string]*string{
+		"format": &ret.Format,
+		"output": &ret.OutputPath,
+		"o":      &ret.OutputPath}
+	for idx, rawArg := range os.Args {
 		var arg string
 		switch {
 		case strings.HasPrefix(rawArg, "--"):
@@ -161,6 +178,23 @@ func ParseProgramArgs() *Arg {
 		if boolPtr, exists := flags[arg]; exists {
 			*boolPtr = true
 		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		if strPtr, exists := valueFlags[name]; exists {
+			switch {
+			case hasValue:
+				*strPtr = value
+			case idx+1 < len(os.Args):
+				*strPtr = os.Args[idx+1]
+			default:
+				fmt.Printf("Missing value for flag: `%v`\n", rawArg)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if ret.Json && ret.Format == "" {
+		ret.Format = "json"
 	}
 
 	lastStr := os.Args[len(os.Args)-1]